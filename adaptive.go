@@ -0,0 +1,62 @@
+package cache
+
+import "time"
+
+// capacity tuning goroutine
+func (c *LRU[K, V]) tuner(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	hits, misses := c.stats.hits.Load(), c.stats.misses.Load()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			h, m := c.stats.hits.Load(), c.stats.misses.Load()
+
+			c.adapt(h-hits, m-misses)
+			hits, misses = h, m
+		}
+	}
+}
+
+// adjust the capacity of the cache given the numbers of hits and misses since the last adjustment
+func (c *LRU[K, V]) adapt(hits, misses uint64) {
+	if hits+misses == 0 {
+		return // no data
+	}
+
+	ratio := float64(hits) / float64(hits+misses)
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	size := c.size
+
+	switch a := &c.adaptive; {
+	case ratio < a.target:
+		size = min(size+max(size/10, 1), a.max)
+	case ratio > a.target:
+		size = max(size-max(size/20, 1), a.min)
+	}
+
+	c.resize(size)
+
+	for len(c.nodes) > c.size && c.evict() {
+	}
+}
+
+// set the capacity of the cache
+func (c *LRU[K, V]) resize(size int) {
+	c.size = size
+
+	// the protected segment takes up to 80% of the capacity
+	if c.maxProt = size * 4 / 5; c.maxProt == 0 {
+		c.maxProt = 1
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveCapacity(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend, WithAdaptiveCapacity(5, 20, 0.8, time.Hour))
+
+	defer c.Close()
+
+	if err := fill(c.Get, []int{1, 2, 3, 4, 5, 6, 7, 8}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	steps := []struct {
+		hits, misses uint64
+		capacity     int
+	}{
+		{0, 0, 10},   // no data
+		{70, 30, 11}, // grow
+		{80, 20, 11}, // on target
+		{10, 0, 10},  // shrink
+		{10, 0, 9},   // shrink
+		{10, 0, 8},   // shrink
+		{10, 0, 7},   // shrink, with eviction
+		{10, 0, 6},   // shrink, with eviction
+		{10, 0, 5},   // shrink, with eviction
+		{10, 0, 5},   // lower bound
+	}
+
+	for i, step := range steps {
+		c.adapt(step.hits, step.misses)
+
+		if s := c.Stats(); s.Capacity != step.capacity || s.Size > s.Capacity {
+			t.Errorf("step %d: unexpected capacity or size: %d, %d", i, s.Capacity, s.Size)
+			return
+		}
+	}
+
+	for range 20 {
+		c.adapt(0, 10)
+	}
+
+	if s := c.Stats(); s.Capacity != 20 {
+		t.Errorf("unexpected capacity: %d instead of 20", s.Capacity)
+		return
+	}
+
+	// invalid parameters
+	if _, err := TryNew(30, time.Hour, simpleBackend, WithAdaptiveCapacity(5, 20, 0.8, time.Hour)); !errors.Is(err, ErrInvalidOption) {
+		t.Error("unexpected error:", err)
+		return
+	}
+}
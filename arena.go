@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"sync"
+)
+
+const (
+	minSlotSize = 64      // size of the smallest arena slot
+	maxSlotSize = 1 << 16 // size of the largest arena slot
+	blockSize   = 1 << 20 // size of a memory block shared by the slots
+	slotHeader  = 4       // size of the slot header holding its generation
+)
+
+// number of slot size classes
+const numSizeClasses = 11 // log2(maxSlotSize / minSlotSize) + 1
+
+// errTooLarge is returned for a value that does not fit into an arena slot
+var errTooLarge = errors.New("value is too large to be stored in the cache")
+
+// reference to a value stored in an arena; it contains no pointers, so the garbage collector
+// does not need to scan it
+type slot struct {
+	block uint32 // index of the memory block
+	off   uint32 // offset of the slot in the block
+	size  uint32 // size of the value
+	gen   uint32 // generation of the slot (0 if empty)
+}
+
+// Size implements Sizer interface, for WithMaxBytes option.
+func (s slot) Size() int {
+	return int(s.size)
+}
+
+// size class of the slot for the value of the given size, or -1 if the value needs a dedicated
+// memory block
+func sizeClass(size int) int {
+	if size += slotHeader; size > maxSlotSize {
+		return -1
+	}
+
+	if size <= minSlotSize {
+		return 0
+	}
+
+	return bits.Len(uint((size - 1) / minSlotSize)) // ceil(log2(size / minSlotSize))
+}
+
+// arena of memory blocks holding values in slots of a few size classes; the memory of the freed
+// slots is reused for the new values of the same class, but never returned to the runtime,
+// except for the dedicated blocks of the values too large for any slot
+type arena struct {
+	mu     sync.RWMutex
+	blocks [][]byte               // memory blocks (nil if released)
+	spare  []uint32               // indices of the released blocks
+	freed  [numSizeClasses][]slot // freed slots per size class
+	tail   int                    // index of the block being carved into slots (-1 if none)
+	used   int                    // number of bytes carved from the tail block
+	gen    uint32                 // last allocated slot generation
+}
+
+// create a new arena
+func newArena() *arena {
+	return &arena{tail: -1}
+}
+
+// copy the data into a new slot
+func (a *arena) alloc(data []byte) (s slot, err error) {
+	if len(data) > math.MaxUint32-slotHeader {
+		return s, errTooLarge
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch class := sizeClass(len(data)); {
+	case class < 0: // dedicated block
+		s.block = a.newBlock(len(data) + slotHeader)
+
+	case len(a.freed[class]) > 0: // reuse a freed slot
+		n := len(a.freed[class]) - 1
+		s, a.freed[class] = a.freed[class][n], a.freed[class][:n]
+
+	default: // carve a new slot
+		size := minSlotSize << class
+
+		if a.tail < 0 || a.used+size > blockSize {
+			a.tail, a.used = int(a.newBlock(blockSize)), 0
+		}
+
+		s.block, s.off = uint32(a.tail), uint32(a.used)
+		a.used += size
+	}
+
+	// zero generation is reserved for empty slots
+	if a.gen++; a.gen == 0 {
+		a.gen = 1
+	}
+
+	s.size, s.gen = uint32(len(data)), a.gen
+
+	mem := a.blocks[s.block][s.off:]
+
+	binary.LittleEndian.PutUint32(mem, s.gen)
+	copy(mem[slotHeader:], data)
+	return
+}
+
+// allocate a new memory block of the given size, returning its index
+func (a *arena) newBlock(size int) (i uint32) {
+	if n := len(a.spare); n > 0 {
+		i, a.spare = a.spare[n-1], a.spare[:n-1]
+		a.blocks[i] = make([]byte, size)
+		return
+	}
+
+	a.blocks = append(a.blocks, make([]byte, size))
+	return uint32(len(a.blocks) - 1)
+}
+
+// free the slot; no-op if the slot has already been freed
+func (a *arena) free(s slot) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.valid(s) {
+		return
+	}
+
+	if class := sizeClass(int(s.size)); class >= 0 {
+		binary.LittleEndian.PutUint32(a.blocks[s.block][s.off:], 0)
+		a.freed[class] = append(a.freed[class], slot{block: s.block, off: s.off})
+	} else {
+		a.blocks[s.block] = nil
+		a.spare = append(a.spare, s.block)
+	}
+}
+
+// copy of the value from the slot, or false if the slot has been freed
+func (a *arena) read(s slot) ([]byte, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.valid(s) {
+		return nil, false
+	}
+
+	start := s.off + slotHeader
+
+	return append([]byte(nil), a.blocks[s.block][start:start+s.size]...), true
+}
+
+// check if the slot holds its value (the arena must be locked)
+func (a *arena) valid(s slot) bool {
+	if s.gen == 0 || int(s.block) >= len(a.blocks) || a.blocks[s.block] == nil {
+		return false
+	}
+
+	return binary.LittleEndian.Uint32(a.blocks[s.block][s.off:]) == s.gen
+}
+
+// total size of the memory blocks
+func (a *arena) size() (n int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, block := range a.blocks {
+		n += len(block)
+	}
+
+	return
+}
+
+// release all the memory
+func (a *arena) reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.blocks, a.spare, a.freed = nil, nil, [numSizeClasses][]slot{}
+	a.tail, a.used = -1, 0
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSizeClass(t *testing.T) {
+	tests := []struct{ size, class int }{
+		{0, 0},
+		{minSlotSize - slotHeader, 0},
+		{minSlotSize - slotHeader + 1, 1},
+		{200, 2},
+		{maxSlotSize - slotHeader, numSizeClasses - 1},
+		{maxSlotSize, -1},
+	}
+
+	for _, test := range tests {
+		if class := sizeClass(test.size); class != test.class {
+			t.Errorf("unexpected size class for %d bytes: %d instead of %d", test.size, class, test.class)
+			return
+		}
+	}
+}
+
+func TestArena(t *testing.T) {
+	a := newArena()
+
+	small, large := bytes.Repeat([]byte("x"), 100), bytes.Repeat([]byte("y"), maxSlotSize)
+
+	s1, err := a.alloc(small)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	s2, err := a.alloc(large)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if size := a.size(); size != blockSize+maxSlotSize+slotHeader {
+		t.Error("unexpected arena size:", size)
+		return
+	}
+
+	for _, s := range []slot{s1, s2} {
+		if data, ok := a.read(s); !ok || !bytes.Equal(data, small) && !bytes.Equal(data, large) {
+			t.Error("unexpected data in slot", s)
+			return
+		}
+	}
+
+	a.free(s1)
+	a.free(s2)
+	a.free(s1) // no-op
+
+	if _, ok := a.read(s1); ok {
+		t.Error("unexpected data in a freed slot")
+		return
+	}
+
+	// the freed slot is reused, but the stale reference is still invalid
+	s3, err := a.alloc(bytes.Repeat([]byte("z"), 90))
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if s3.block != s1.block || s3.off != s1.off {
+		t.Error("the freed slot has not been reused:", s3)
+		return
+	}
+
+	if _, ok := a.read(s1); ok {
+		t.Error("unexpected data in a stale slot")
+		return
+	}
+
+	if data, ok := a.read(s3); !ok || string(data) != strings.Repeat("z", 90) {
+		t.Error("unexpected data:", string(data))
+		return
+	}
+
+	// the dedicated block is released
+	if size := a.size(); size != blockSize {
+		t.Error("unexpected arena size:", size)
+		return
+	}
+}
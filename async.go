@@ -0,0 +1,40 @@
+package cache
+
+import "runtime/debug"
+
+// Result is the outcome of an asynchronous cache request.
+type Result[V any] struct {
+	Value V     // value
+	Err   error // error
+}
+
+// GetAsync retrieves the value associated with the given key in a separate goroutine,
+// returning a channel that delivers the result once it is available. The channel is
+// buffered, so the result does not have to be received at all.
+func (c *LRU[K, V]) GetAsync(key K) <-chan Result[V] {
+	res := make(chan Result[V], 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				res <- Result[V]{Err: &PanicError{Value: p, Stack: debug.Stack()}}
+			}
+		}()
+
+		value, err := c.Get(key)
+
+		res <- Result[V]{value, err}
+	}()
+
+	return res
+}
+
+// Prefetch schedules loading of the given key into the cache, without waiting for the result.
+// A panic in the backend function is suppressed.
+func (c *LRU[K, V]) Prefetch(key K) {
+	go func() {
+		defer func() { recover() }()
+
+		c.Get(key)
+	}()
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetAsync(t *testing.T) {
+	c := New(10, time.Hour, func(key int) (int, error) {
+		if key == 5 {
+			panic("key 5")
+		}
+
+		return simpleBackend(key)
+	})
+
+	if res := <-c.GetAsync(1); res.Err != nil || res.Value != -1 {
+		t.Error("unexpected result:", res)
+		return
+	}
+
+	if res := <-c.GetAsync(1000); res.Err == nil {
+		t.Error("missing error")
+		return
+	}
+
+	if res := <-c.GetAsync(5); res.Err == nil {
+		t.Error("missing error")
+		return
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	loaded := make(chan int, 10)
+
+	c := New(10, time.Hour, func(key int) (int, error) {
+		loaded <- key
+		return simpleBackend(key)
+	})
+
+	c.Prefetch(1)
+
+	select {
+	case k := <-loaded:
+		if k != 1 {
+			t.Error("unexpected key:", k)
+			return
+		}
+	case <-time.After(time.Second):
+		t.Error("the key has not been loaded")
+		return
+	}
+
+	if v, err := c.Get(1); err != nil || v != -1 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	if len(loaded) != 0 {
+		t.Error("the key has been loaded twice")
+		return
+	}
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned from the cache when the backend has not provided a value for the key.
+// Backend functions can return the error, possibly wrapped, to report a missing key, which is
+// then not counted as a load error, and is never retried (see also WithNotFoundTTL).
+var ErrNotFound = errors.New("key not found")
+
+// NewBatched creates a new LRU cache with keys of type "K" and values of type "V", where the
+// backend function fetches multiple keys in one call. Cache misses are collected into a batch
+// for up to the given time window after the first miss, or until the batch reaches the given
+// max. size, and then fetched together. A key missing from the map returned by the backend
+// gets ErrNotFound error, while an error from the backend is returned for all the keys
+// of the batch.
+func NewBatched[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	backend func([]K) (map[K]V, error),
+	window time.Duration,
+	maxBatch int,
+	opts ...Option,
+) *LRU[K, V] {
+	if backend == nil {
+		panic("attempt to create an LRU cache with nil backend function")
+	}
+
+	if window < 0 {
+		panic("attempt to create an LRU cache with negative batching window")
+	}
+
+	if maxBatch < 1 {
+		panic("attempt to create an LRU cache with invalid max. batch size of " + strconv.Itoa(maxBatch))
+	}
+
+	b := &batcher[K, V]{backend: backend, window: window, maxBatch: maxBatch}
+
+	return New(size, ttl, b.load, opts...)
+}
+
+// collector of keys into batches
+type batcher[K comparable, V any] struct {
+	backend  func([]K) (map[K]V, error) // bulk backend function
+	window   time.Duration              // max. time to collect a batch
+	maxBatch int                        // max. number of keys in a batch
+
+	mu  sync.Mutex   // protects the current batch
+	cur *batch[K, V] // batch being collected (nil if none)
+}
+
+// batch of keys
+type batch[K comparable, V any] struct {
+	keys   []K           // keys to fetch
+	values map[K]V       // fetched values
+	err    error         // backend error
+	panic  any           // backend panic
+	done   chan struct{} // closed when the batch has been fetched
+}
+
+// per-key backend function
+func (b *batcher[K, V]) load(key K) (value V, err error) {
+	b.mu.Lock()
+
+	bt := b.cur
+
+	if bt == nil {
+		bt = &batch[K, V]{done: make(chan struct{})}
+		b.cur = bt
+
+		time.AfterFunc(b.window, func() { b.flush(bt) })
+	}
+
+	if bt.keys = append(bt.keys, key); len(bt.keys) < b.maxBatch {
+		b.mu.Unlock()
+	} else {
+		b.cur = nil
+		b.mu.Unlock()
+		b.fetch(bt)
+	}
+
+	<-bt.done
+
+	if bt.panic != nil {
+		panic(bt.panic)
+	}
+
+	if bt.err != nil {
+		return value, bt.err
+	}
+
+	value, found := bt.values[key]
+
+	if !found {
+		err = ErrNotFound
+	}
+
+	return
+}
+
+// fetch the batch if it is still being collected
+func (b *batcher[K, V]) flush(bt *batch[K, V]) {
+	b.mu.Lock()
+
+	if b.cur != bt {
+		b.mu.Unlock()
+		return
+	}
+
+	b.cur = nil
+	b.mu.Unlock()
+	b.fetch(bt)
+}
+
+// invoke the backend for the batch
+func (b *batcher[K, V]) fetch(bt *batch[K, V]) {
+	defer close(bt.done)
+
+	defer func() {
+		bt.panic = recover()
+	}()
+
+	bt.values, bt.err = b.backend(bt.keys)
+}
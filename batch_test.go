@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatched(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+
+	backend := func(keys []int) (map[int]int, error) {
+		mu.Lock()
+		batches = append(batches, slices.Sorted(slices.Values(keys)))
+		mu.Unlock()
+
+		res := make(map[int]int, len(keys))
+
+		for _, k := range keys {
+			if validKey(k) {
+				res[k] = -k
+			}
+		}
+
+		return res, nil
+	}
+
+	// concurrent misses within the window are fetched together
+	c := NewBatched(100, time.Hour, backend, 50*time.Millisecond, 100)
+
+	if err := getConcurrently(c, []int{1, 2, 3, 1000}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if s := fmt.Sprint(batches); s != "[[1 2 3 1000]]" {
+		t.Error("unexpected batches:", s)
+		return
+	}
+
+	if _, err := c.Get(1000); err != ErrNotFound {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// batch size limit
+	batches = nil
+	c = NewBatched(100, time.Hour, backend, time.Hour, 2)
+
+	if err := getConcurrently(c, []int{1, 2, 3, 4}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 2 {
+		t.Error("unexpected batches:", batches)
+		return
+	}
+}
+
+func TestBatchedError(t *testing.T) {
+	errBackend := errors.New("backend failure")
+
+	c := NewBatched(100, time.Hour, func(keys []int) (map[int]int, error) {
+		return nil, errBackend
+	}, time.Millisecond, 10)
+
+	if _, err := c.Get(1); err != errBackend {
+		t.Error("unexpected error:", err)
+		return
+	}
+}
+
+// get the keys concurrently, checking the values
+func getConcurrently(c *LRU[int, int], keys []int) error {
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+
+	for i, k := range keys {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v, err := c.Get(k)
+
+			switch {
+			case err == nil && v != -k:
+				errs[i] = fmt.Errorf("key %d: unexpected value %d", k, v)
+			case err != nil && (validKey(k) || err != ErrNotFound):
+				errs[i] = fmt.Errorf("key %d: unexpected error: %w", k, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
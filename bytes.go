@@ -0,0 +1,119 @@
+package cache
+
+import "time"
+
+// Bytes is an opaque type representing an LRU cache with keys of type "K" and []byte values,
+// where the values are stored in large pre-allocated blocks of memory (arenas), instead of
+// separate heap objects, so that even millions of cached values add nothing for the garbage
+// collector to track. The memory of the evicted values is reused for the new ones, but it is
+// never returned to the runtime before the cache is closed, except for the values larger than
+// 64KiB, each stored in its own block. The values are copied in and out of the arena, so the
+// backend function can reuse its buffers, and the callers can modify the returned slices.
+type Bytes[K comparable] struct {
+	cache *LRU[K, slot] // underlying cache
+	arena *arena        // storage for the values
+}
+
+// NewBytes creates a new LRU cache with keys of type "K" and []byte values stored in arenas.
+// The options requiring functions of the value type are not supported, except for WithOnEvict,
+// where the value type is []byte, and WithMaxBytes option limits the total size of the values.
+func NewBytes[K comparable](
+	size int,
+	ttl time.Duration,
+	backend func(K) ([]byte, error),
+	opts ...Option,
+) *Bytes[K] {
+	if backend == nil {
+		panic("attempt to create an LRU cache with nil backend function")
+	}
+
+	c := &Bytes[K]{arena: newArena()}
+
+	// the slot of each value leaving the cache is freed after the eviction callback, if any
+	opts = append(opts[:len(opts):len(opts)], func(cfg *config) {
+		var onEvict func(K, []byte, EvictReason)
+
+		switch fn := cfg.onEvict.(type) {
+		case nil:
+			// ok
+		case func(K, []byte, EvictReason):
+			onEvict = fn
+		default:
+			return // rejected by the option validation
+		}
+
+		cfg.onEvict = func(key K, s slot, reason EvictReason) {
+			if onEvict != nil {
+				if data, ok := c.arena.read(s); ok {
+					onEvict(key, data, reason)
+				}
+			}
+
+			c.arena.free(s)
+		}
+	})
+
+	c.cache = New(size, ttl, func(key K) (s slot, err error) {
+		data, err := backend(key)
+
+		if err != nil {
+			return
+		}
+
+		return c.arena.alloc(data)
+	}, opts...)
+
+	return c
+}
+
+// Get retrieves the value associated with the given key, invoking backend where necessary.
+func (c *Bytes[K]) Get(key K) ([]byte, error) {
+	for {
+		s, expires, err := c.cache.GetWithExpiry(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data, ok := c.arena.read(s)
+
+		// the value not admitted into the cache (see WithDoorkeeper) never gets evicted,
+		// so its slot is freed here, and the concurrent callers sharing the load retry
+		if expires.IsZero() {
+			c.arena.free(s)
+		}
+
+		if ok {
+			return data, nil
+		}
+
+		// the value has been evicted in the meantime
+	}
+}
+
+// Delete evicts the given key from the cache.
+func (c *Bytes[K]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+// Len returns the current number of items in the cache.
+func (c *Bytes[K]) Len() int {
+	return c.cache.Len()
+}
+
+// Stats returns statistics of the cache.
+func (c *Bytes[K]) Stats() Stats {
+	return c.cache.Stats()
+}
+
+// ArenaSize returns the total size of the memory blocks allocated for the values.
+func (c *Bytes[K]) ArenaSize() int {
+	return c.arena.size()
+}
+
+// Close closes the cache (see LRU.Close), and releases the memory of the arenas.
+func (c *Bytes[K]) Close() (err error) {
+	err = c.cache.Close()
+	c.arena.reset()
+	return
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	var (
+		evicted []string
+		mu      sync.Mutex
+	)
+
+	backend := func(key int) ([]byte, error) {
+		if key < 0 {
+			return nil, ErrNotFound
+		}
+
+		return bytes.Repeat([]byte(strconv.Itoa(key)), 100), nil
+	}
+
+	c := NewBytes(10, time.Hour, backend, WithOnEvict(func(key int, data []byte, _ EvictReason) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !bytes.Equal(data, bytes.Repeat([]byte(strconv.Itoa(key)), 100)) {
+			t.Error("unexpected evicted value for key", key)
+		}
+
+		evicted = append(evicted, strconv.Itoa(key))
+	}))
+
+	defer c.Close()
+
+	if _, err := c.Get(-1); err != ErrNotFound {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// concurrent access with evictions
+	var wg sync.WaitGroup
+
+	for i := range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for k := range 1000 {
+				key := (k*(i+1) + i) % 50
+
+				data, err := c.Get(key)
+
+				if err != nil {
+					t.Error("unexpected error:", err)
+					return
+				}
+
+				if exp, _ := backend(key); !bytes.Equal(data, exp) {
+					t.Errorf("unexpected value for key %d: %q", key, data)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// the memory of the evicted values is reused
+	if size := c.ArenaSize(); size != blockSize {
+		t.Error("unexpected arena size:", size)
+		return
+	}
+
+	mu.Lock()
+	n := len(evicted)
+	mu.Unlock()
+
+	// the cached error is evicted, but not reported
+	if s := c.Stats(); n == 0 || uint64(n)+1 != s.EvictedCapacity {
+		t.Errorf("unexpected number of evictions: %d, stats: %+v", n, s)
+		return
+	}
+
+	// the values returned are copies
+	data, err := c.Get(1)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	data[0] = 'x'
+
+	if data, _ = c.Get(1); data[0] != '1' {
+		t.Error("the cached value has been modified")
+		return
+	}
+}
+
+func TestBytesNotAdmitted(t *testing.T) {
+	backend := func(key int) ([]byte, error) {
+		return bytes.Repeat([]byte{byte(key)}, 1000), nil
+	}
+
+	c := NewBytes(10, time.Hour, backend, WithDoorkeeper(100))
+
+	defer c.Close()
+
+	// the keys requested only once are never cached
+	for k := range 10000 {
+		data, err := c.Get(k)
+
+		if err != nil || !bytes.Equal(data, bytes.Repeat([]byte{byte(k)}, 1000)) {
+			t.Error("unexpected result for key", k, err)
+			return
+		}
+	}
+
+	// the slots of the values not admitted get reused
+	if n := c.ArenaSize(); n > blockSize {
+		t.Errorf("unexpected arena size: %d", n)
+		return
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// Level describes one level of a chained cache (see Chain).
+type Level struct {
+	Size    int           // capacity of the level
+	TTL     time.Duration // max. time-to-live of the items at the level
+	Options []Option      // options of the level
+}
+
+// Chained is an opaque type representing a multi-level cache, where each level is an LRU cache
+// filled from the level below it, and the bottom level is filled from the backend.
+type Chained[K comparable, V any] struct {
+	levels []*LRU[K, V] // levels of the cache, top first
+}
+
+// Chain creates a new multi-level cache with keys of type "K" and values of type "V", from the
+// given levels, listed from the top (typically the smallest and the shortest-lived) to the bottom.
+// On a miss at any level, the value is fetched from the level below it, with the time-to-live
+// of the item limited to the remaining lifetime of the item at the lower level, so that no level
+// serves a value for longer than the levels below it. Errors are passed up the chain, and get
+// cached at each level according to its options.
+func Chain[K comparable, V any](backend func(K) (V, error), levels ...Level) *Chained[K, V] {
+	if backend == nil {
+		panic("attempt to create a cache chain with nil backend function")
+	}
+
+	if len(levels) == 0 {
+		panic("attempt to create a cache chain with no levels")
+	}
+
+	c := &Chained[K, V]{levels: make([]*LRU[K, V], len(levels))}
+
+	// build from the bottom up
+	last := len(levels) - 1
+
+	c.levels[last] = New(levels[last].Size, levels[last].TTL, backend, levels[last].Options...)
+
+	for i := last - 1; i >= 0; i-- {
+		lower, maxTTL := c.levels[i+1], levels[i].TTL
+
+		c.levels[i] = NewWithTTL(levels[i].Size, maxTTL, func(key K) (V, time.Duration, error) {
+			value, expires, err := lower.GetWithExpiry(key)
+
+			var ttl time.Duration // the default TTL of the level
+
+			// no more than the remaining lifetime at the lower level, if cached there
+			if !expires.IsZero() {
+				if ttl = max(expires.Sub(lower.now()), 1); maxTTL > 0 && ttl > maxTTL {
+					ttl = 0
+				}
+			}
+
+			return value, ttl, err
+		}, levels[i].Options...)
+	}
+
+	return c
+}
+
+// Get retrieves the value associated with the given key, from the highest level of the cache
+// that has it, or from the backend.
+func (c *Chained[K, V]) Get(key K) (V, error) {
+	return c.levels[0].Get(key)
+}
+
+// Delete evicts the given key from all the levels of the cache, from the bottom up, so that
+// the upper levels cannot be refilled with the old value.
+func (c *Chained[K, V]) Delete(key K) {
+	for i := len(c.levels) - 1; i >= 0; i-- {
+		c.levels[i].Delete(key)
+	}
+}
+
+// Stats returns statistics of each level of the cache, top first.
+func (c *Chained[K, V]) Stats() []Stats {
+	stats := make([]Stats, len(c.levels))
+
+	for i, level := range c.levels {
+		stats[i] = level.Stats()
+	}
+
+	return stats
+}
+
+// Close closes all the levels of the cache (see LRU.Close), returning the errors from all
+// the levels, if any.
+func (c *Chained[K, V]) Close() error {
+	var errs []error
+
+	for _, level := range c.levels {
+		if err := level.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChain(t *testing.T) {
+	var backend tracingBackend
+
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	c := Chain(backend.fn,
+		Level{Size: 2, TTL: time.Second, Options: []Option{clock}},
+		Level{Size: 10, TTL: 5 * time.Minute, Options: []Option{clock}})
+
+	if err := fill(c.Get, []int{1, 2, 3, 1000, 1, 2, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// the lower level serves the items evicted from the top level
+	if err := matchTraces(backend.trace, []int{1, 2, 3, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if stats := c.Stats(); stats[0].Size != 2 || stats[1].Size != 4 || stats[1].Hits != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+		return
+	}
+
+	// the top level items do not outlive the lower level ones
+	now = now.Add(5*time.Minute - 500*time.Millisecond)
+
+	if err := fill(c.Get, []int{1}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if ttl := c.levels[0].nodes[1].lifetime(); ttl != 500*time.Millisecond {
+		t.Errorf("unexpected TTL at the top level: %s instead of 500ms", ttl)
+		return
+	}
+
+	now = now.Add(500 * time.Millisecond)
+
+	if err := fill(c.Get, []int{1}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 3, 1000, 1}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	// deletion from all the levels
+	c.Delete(1)
+
+	if err := fill(c.Get, []int{1}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 3, 1000, 1, 1}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if err := c.Close(); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+}
@@ -0,0 +1,21 @@
+package cache
+
+import "time"
+
+// coarse clock goroutine
+func (c *LRU[K, V]) ticker(resolution time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(resolution)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.coarse.Store(&now)
+		}
+	}
+}
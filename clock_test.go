@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCoarseClock(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, 20*time.Millisecond, backend.fn, WithCoarseClock(2*time.Millisecond))
+
+	defer c.Close()
+
+	if err := fill(c.Get, []int{1, 2, 1}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	start := c.now()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if now := c.now(); !now.After(start) {
+		t.Error("coarse clock has not been updated")
+		return
+	}
+
+	// the items have expired by the coarse clock
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 1, 2}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	// invalid options
+	_, err := TryNew(10, time.Hour, simpleBackend, WithCoarseClock(-time.Second))
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Error("unexpected error for negative resolution:", err)
+		return
+	}
+
+	_, err = TryNew(10, time.Hour, simpleBackend, WithCoarseClock(time.Second), WithClock(time.Now))
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Error("unexpected error for coarse clock with clock function:", err)
+		return
+	}
+}
@@ -0,0 +1,41 @@
+package cache
+
+import "maps"
+
+// Clone creates a new cache with the same parameters and options as this one, and copies all
+// the valid (i.e., successfully fetched and not expired) items into it, in their LRU order and
+// with their original timestamps and expiration times, for example, to fork a request-scoped
+// cache from a long-lived one. The values are copied using the given function, if not nil,
+// or as they are otherwise. The pinned keys stay pinned in the new cache, while the statistics
+// and the writes queued for the backend (see WithWriteBack) are not copied. The new cache runs
+// its own background goroutines, if any, so it has to be closed independently.
+func (c *LRU[K, V]) Clone(copyValue func(V) V) *LRU[K, V] {
+	size := c.size
+
+	if c.unbounded {
+		size = min(max(c.Len(), 1), maxCacheSize) // just a hint
+	}
+
+	cfg := c.config
+
+	clone, err := tryNewLRU(size, c.ttl, c.source, []Option{func(p *config) { *p = cfg }})
+
+	if err != nil { // the same parameters have already been validated
+		panic(err.Error())
+	}
+
+	recs := c.records()
+
+	if copyValue != nil {
+		for i := range recs {
+			recs[i].Value = copyValue(recs[i].Value)
+		}
+	}
+
+	c.mu.Lock()
+	clone.pins = maps.Clone(c.pins)
+	c.mu.Unlock()
+
+	clone.restore(recs)
+	return clone
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(4, time.Hour, backend.fn, WithPolicy(PolicySLRU))
+
+	defer c.Close()
+
+	if err := fill(c.Get, []int{1, 2, 1000, 3, 1}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.Pin(2)
+
+	clone := c.Clone(func(v int) int { return v * 10 })
+
+	defer clone.Close()
+
+	if keys := fmt.Sprint(clone.OrderedKeys()); keys != "[2 1 3]" {
+		t.Errorf("unexpected keys: %s instead of [2 1 3]", keys)
+		return
+	}
+
+	if v, err := clone.Get(1); err != nil || v != -10 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	// the same backend and options
+	if err := fill(clone.Get, []int{4, 5}, validKey); err != nil {
+		t.Error("error reading the clone:", err)
+		return
+	}
+
+	if keys := fmt.Sprint(clone.OrderedKeys()); keys != "[2 1 5 4]" {
+		t.Errorf("unexpected keys: %s instead of [2 1 5 4]", keys)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 1000, 3, 4, 5}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	// the original is not affected
+	if keys := fmt.Sprint(c.OrderedKeys()); keys != "[2 1 3]" {
+		t.Errorf("unexpected keys: %s instead of [2 1 3]", keys)
+		return
+	}
+}
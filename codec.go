@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Codec converts cache entries to and from their binary representation, for persisting
+// the cache in a custom format (see SnapshotWith and RestoreWith).
+type Codec[K comparable, V any] interface {
+	// Encode returns the binary representation of the entry, or ErrSkipEntry if the entry
+	// cannot or should not be persisted.
+	Encode(e *Entry[K, V]) ([]byte, error)
+
+	// Decode reconstructs the entry from its binary representation, or returns ErrSkipEntry
+	// if the entry is to be ignored.
+	Decode(data []byte, e *Entry[K, V]) error
+}
+
+// ErrSkipEntry is returned from a Codec to skip the entry instead of failing the whole
+// snapshot or restore.
+var ErrSkipEntry = errors.New("skip cache entry")
+
+// JSONCodec is a Codec using encoding/json.
+type JSONCodec[K comparable, V any] struct{}
+
+// Encode implements Codec interface.
+func (JSONCodec[K, V]) Encode(e *Entry[K, V]) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Decode implements Codec interface.
+func (JSONCodec[K, V]) Decode(data []byte, e *Entry[K, V]) error {
+	return json.Unmarshal(data, e)
+}
+
+// SnapshotWith is the same as Snapshot, but encodes the items using the given codec.
+// Each encoded item is written to the stream with its length prepended as uvarint.
+func (c *LRU[K, V]) SnapshotWith(w io.Writer, codec Codec[K, V]) (err error) {
+	out := bufio.NewWriter(w)
+
+	var buff []byte
+
+	for _, rec := range c.records() {
+		if buff, err = writeRecord(out, codec, &rec, buff); err != nil {
+			return
+		}
+	}
+
+	return out.Flush()
+}
+
+// RestoreWith is the same as Restore, but reads the items written by SnapshotWith,
+// decoding them using the given codec.
+func (c *LRU[K, V]) RestoreWith(r io.Reader, codec Codec[K, V]) error {
+	var recs []Entry[K, V]
+
+	err := readRecords(r, codec, func(rec *Entry[K, V]) {
+		recs = append(recs, *rec)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	c.restore(recs)
+	return nil
+}
+
+// encode the record and write it to the stream with its length prepended, unless the codec
+// skips the record; returns the buffer for reuse
+func writeRecord[K comparable, V any](out io.Writer, codec Codec[K, V], rec *Entry[K, V], buff []byte) ([]byte, error) {
+	data, err := codec.Encode(rec)
+
+	switch err {
+	case nil:
+		// ok
+	case ErrSkipEntry:
+		return buff, nil
+	default:
+		return buff, err
+	}
+
+	buff = binary.AppendUvarint(buff[:0], uint64(len(data)))
+
+	if _, err = out.Write(buff); err == nil {
+		_, err = out.Write(data)
+	}
+
+	return buff, err
+}
+
+// ErrRecordTooLarge is returned from RestoreWith and Import when the length of a record
+// in the stream exceeds the limit of 1GB, which indicates corrupted input.
+var ErrRecordTooLarge = errors.New("cache record too large")
+
+// max. length of a record in the stream
+const maxRecordSize = 1 << 30
+
+// read the records from the stream until EOF, passing each decoded one to the given function
+func readRecords[K comparable, V any](r io.Reader, codec Codec[K, V], fn func(*Entry[K, V])) error {
+	var data bytes.Buffer
+
+	in := bufio.NewReader(r)
+
+	for {
+		n, err := binary.ReadUvarint(in)
+
+		switch err {
+		case nil:
+			// ok
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+
+		if n > maxRecordSize {
+			return ErrRecordTooLarge
+		}
+
+		// the buffer grows with the data actually read, so a corrupted length
+		// does not cause a huge allocation
+		data.Reset()
+
+		if _, err = io.CopyN(&data, in, int64(n)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return err
+		}
+
+		var rec Entry[K, V]
+
+		switch err = codec.Decode(data.Bytes(), &rec); err {
+		case nil:
+			fn(&rec)
+		case ErrSkipEntry:
+			// skip
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestSnapshotWith(t *testing.T) {
+	c := New(5, time.Hour, simpleBackend)
+
+	if err := fill(c.Get, []int{1, 2, 3, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	var buff bytes.Buffer
+
+	if err := c.SnapshotWith(&buff, oddCodec{}); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	c2 := New(5, time.Hour, simpleBackend)
+
+	if err := c2.RestoreWith(bytes.NewReader(buff.Bytes()), oddCodec{}); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if keys := fmt.Sprint(listKeys(&c2.list)); keys != "[1 3]" {
+		t.Errorf("unexpected keys: %s instead of [1 3]", keys)
+		return
+	}
+
+	if node := c2.nodes[3]; node.value != -3 || !node.ts.Equal(c.nodes[3].ts) {
+		t.Error("unexpected node:", node.value, node.ts)
+		return
+	}
+
+	// truncated stream
+	if err := c2.RestoreWith(bytes.NewReader(buff.Bytes()[:buff.Len()-1]), oddCodec{}); err == nil {
+		t.Error("missing error")
+		return
+	}
+
+	// corrupted length
+	for _, n := range []uint64{1 << 62, 1 << 29} {
+		data := binary.AppendUvarint(nil, n)
+
+		err := c2.RestoreWith(bytes.NewReader(append(data, "{}"...)), oddCodec{})
+
+		if n > maxRecordSize && !errors.Is(err, ErrRecordTooLarge) ||
+			n <= maxRecordSize && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("unexpected error for length %d: %v", n, err)
+			return
+		}
+	}
+}
+
+// JSON codec that skips even keys
+type oddCodec struct {
+	JSONCodec[int, int]
+}
+
+func (c oddCodec) Encode(e *Entry[int, int]) ([]byte, error) {
+	if e.Key&1 == 0 {
+		return nil, ErrSkipEntry
+	}
+
+	return c.JSONCodec.Encode(e)
+}
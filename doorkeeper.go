@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+// WithDoorkeeper enables a bloom filter in front of the cache, so that an item is only admitted
+// into the cache when its key is requested at least twice within a window of the given number
+// of distinct keys. On the first request the data is still fetched from the backend and returned
+// to the callers, but not cached, so that the keys requested only once do not evict the more
+// valuable items. The filter takes about one byte per key of the window, and is cleared every
+// time the window fills up. The number of items not admitted is reported in Stats.Rejected.
+func WithDoorkeeper(n int) Option {
+	return func(cfg *config) {
+		cfg.door = n
+	}
+}
+
+// number of bits set per key in the doorkeeper filter
+const doorkeeperProbes = 4
+
+// bloom filter of the keys seen within the current window (the cache must be locked)
+type doorkeeper struct {
+	bits  []uint64     // filter bits
+	mask  uint64       // bit index mask
+	count int          // number of keys added in the current window
+	limit int          // max. number of keys in the window
+	seed  maphash.Seed // hash seed
+}
+
+// create a new doorkeeper for the given window size
+func newDoorkeeper(limit int) *doorkeeper {
+	// about 8 bits per key, rounded up to a power of 2
+	n := max(uint64(1)<<bits.Len64(uint64(limit)*8-1), 64)
+
+	return &doorkeeper{
+		bits:  make([]uint64, n/64),
+		mask:  n - 1,
+		limit: limit,
+		seed:  maphash.MakeSeed(),
+	}
+}
+
+// check if the key with the given hash has already been seen within the current window,
+// adding it to the filter if not
+func (d *doorkeeper) admit(h uint64) bool {
+	// double hashing
+	h1, h2 := h, h>>32|h<<32|1
+	seen := true
+
+	for i := range uint64(doorkeeperProbes) {
+		b := (h1 + i*h2) & d.mask
+		w, m := &d.bits[b/64], uint64(1)<<(b%64)
+
+		if *w&m == 0 {
+			*w |= m
+			seen = false
+		}
+	}
+
+	if !seen {
+		if d.count++; d.count >= d.limit {
+			clear(d.bits)
+			d.count = 0
+		}
+	}
+
+	return seen
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"hash/maphash"
+	"testing"
+	"time"
+)
+
+func TestDoorkeeper(t *testing.T) {
+	var b tracingBackend
+
+	c := New(10, time.Hour, b.fn, WithDoorkeeper(100))
+
+	// the first request for each key is not cached
+	if err := fill(c.Get, []int{1, 2, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if n := c.Len(); n != 0 {
+		t.Error("unexpected cache size:", n)
+		return
+	}
+
+	// the second request is admitted
+	if err := fill(c.Get, []int{1, 2, 1, 2}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := matchTraces(b.trace, []int{1, 2, 3, 1, 2}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := checkState(c, []int{1, 2}, validKey); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if s := c.Stats(); s.Rejected != 3 || s.Misses != 5 || s.Hits != 2 {
+		t.Errorf("unexpected statistics: %+v", s)
+		return
+	}
+}
+
+func TestDoorkeeperWindow(t *testing.T) {
+	d := newDoorkeeper(1000)
+
+	if len(d.bits) != 128 {
+		t.Error("unexpected filter size:", len(d.bits))
+		return
+	}
+
+	hash := func(key int) uint64 { return maphash.Comparable(d.seed, key) }
+
+	for i := range 20 {
+		if d.admit(hash(i)) {
+			t.Error("unexpected admission of key", i)
+			return
+		}
+	}
+
+	for i := range 20 {
+		if !d.admit(hash(i)) {
+			t.Error("key not admitted:", i)
+			return
+		}
+	}
+
+	// fill up the window
+	for i := 100; d.count > 0; i++ {
+		d.admit(hash(i))
+	}
+
+	if d.admit(hash(0)) {
+		t.Error("unexpected admission after reset")
+		return
+	}
+}
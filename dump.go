@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+	"unsafe"
+)
+
+// Dump writes a human-readable listing of the cache entries to the given writer, from the most
+// to the least recently used (with pinned items first, followed by the protected segment under
+// SLRU policy). For each entry, the listing shows its age, time until expiry, and either its
+// state or the error; values are only included if "values" is true. The cache is locked only
+// while copying the entries, not while writing them out. The listing is meant for debugging,
+// and its format may change.
+func (c *LRU[K, V]) Dump(w io.Writer, values bool) error {
+	now := c.now()
+	entries := c.entries()
+	out := bufio.NewWriter(w)
+
+	for _, e := range entries {
+		fmt.Fprintf(out, "%v: age %s, expires in %s", e.key, now.Sub(e.ts).Round(time.Millisecond),
+			e.deadline.Sub(now).Round(time.Millisecond))
+
+		switch {
+		case !e.ready:
+			fmt.Fprint(out, ", loading")
+		case e.err != nil:
+			fmt.Fprintf(out, ", error: %v", e.err)
+		case values:
+			fmt.Fprintf(out, ", value: %v", e.value)
+		}
+
+		fmt.Fprintln(out)
+	}
+
+	return out.Flush()
+}
+
+// snapshot of a cache entry
+type entry[K comparable, V any] struct {
+	key      K
+	value    V
+	err      error
+	ts       time.Time
+	deadline time.Time
+	ready    bool
+}
+
+// copy the cache entries in LRU order, most recent first
+func (c *LRU[K, V]) entries() []entry[K, V] {
+	c.mu.Lock()
+	defer c.unlock()
+
+	entries := make([]entry[K, V], 0, len(c.nodes))
+
+	for _, root := range c.lists() {
+		for p := root.next; p != root; p = p.next {
+			node := (*lruNode[K, V])(unsafe.Pointer(p))
+
+			if !c.current(node) {
+				continue
+			}
+
+			e := entry[K, V]{key: node.key, ts: node.ts, deadline: node.deadline(), ready: node.ready}
+
+			// the data fields are only safe to read once the node is ready
+			if node.ready {
+				e.value, e.err = node.value, node.err
+			}
+
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
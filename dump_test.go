@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDump(t *testing.T) {
+	c := New(3, time.Hour, simpleBackend)
+
+	if err := fill(c.Get, []int{1, 1000, 2, 1}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	var buff strings.Builder
+
+	if err := c.Dump(&buff, true); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	exp := []*regexp.Regexp{
+		regexp.MustCompile(`^1: age \S+, expires in \S+, value: -1$`),
+		regexp.MustCompile(`^2: age \S+, expires in \S+, value: -2$`),
+		regexp.MustCompile(`^1000: age \S+, expires in \S+, error: key not found: 1000$`),
+	}
+
+	if len(lines) != len(exp) {
+		t.Errorf("unexpected number of lines: %d instead of %d", len(lines), len(exp))
+		return
+	}
+
+	for i, re := range exp {
+		if !re.MatchString(lines[i]) {
+			t.Errorf("line %d: unexpected content: %q", i, lines[i])
+			return
+		}
+	}
+
+	buff.Reset()
+
+	if err := c.Dump(&buff, false); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if strings.Contains(buff.String(), "value:") {
+		t.Error("unexpected values in the dump:", buff.String())
+		return
+	}
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ValueCodec converts values of type "V" to and from the form they are stored in (see NewEncoded).
+type ValueCodec[V any] struct {
+	Encode func(V) ([]byte, error) // function to encode a value
+	Decode func([]byte) (V, error) // function to decode a value
+}
+
+// JSONValues returns the codec storing values in JSON format.
+func JSONValues[V any]() ValueCodec[V] {
+	return ValueCodec[V]{
+		Encode: func(value V) ([]byte, error) {
+			return json.Marshal(value)
+		},
+		Decode: func(data []byte) (value V, err error) {
+			err = json.Unmarshal(data, &value)
+			return
+		},
+	}
+}
+
+// Gzip returns the codec compressing the output of the given codec with gzip, at the given
+// compression level (see compress/gzip package).
+func Gzip[V any](codec ValueCodec[V], level int) ValueCodec[V] {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		panic("attempt to create a gzip codec with invalid compression level " + strconv.Itoa(level))
+	}
+
+	return ValueCodec[V]{
+		Encode: func(value V) ([]byte, error) {
+			data, err := codec.Encode(value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			var buff bytes.Buffer
+
+			w, _ := gzip.NewWriterLevel(&buff, level) // the level has been validated
+
+			if _, err = w.Write(data); err == nil {
+				err = w.Close()
+			}
+
+			return buff.Bytes(), err
+		},
+		Decode: func(data []byte) (value V, err error) {
+			r, err := gzip.NewReader(bytes.NewReader(data))
+
+			if err != nil {
+				return
+			}
+
+			if data, err = io.ReadAll(r); err == nil {
+				value, err = codec.Decode(data)
+			}
+
+			return
+		},
+	}
+}
+
+// Encoded is an opaque type representing an LRU cache with keys of type "K" and values of type
+// "V", where the values are stored in encoded form, for example, compressed, and decoded on every
+// cache hit, trading CPU time for memory.
+type Encoded[K comparable, V any] struct {
+	cache  *LRU[K, []byte]         // underlying cache
+	decode func([]byte) (V, error) // function to decode values
+}
+
+// NewEncoded creates a new LRU cache with keys of type "K" and values of type "V", stored in
+// the form produced by the given codec. An error from encoding a value fetched from the backend
+// is treated as a backend error. The options requiring functions of the value type (like
+// WithOnEvict) must use []byte as the value type, and WithMaxBytes option limits the total size
+// of the encoded values.
+func NewEncoded[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	backend func(K) (V, error),
+	codec ValueCodec[V],
+	opts ...Option,
+) *Encoded[K, V] {
+	if codec.Encode == nil || codec.Decode == nil {
+		panic("attempt to create an LRU cache with invalid value codec")
+	}
+
+	if backend == nil {
+		panic("attempt to create an LRU cache with nil backend function")
+	}
+
+	return &Encoded[K, V]{
+		cache: New(size, ttl, func(key K) ([]byte, error) {
+			value, err := backend(key)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return codec.Encode(value)
+		}, opts...),
+		decode: codec.Decode,
+	}
+}
+
+// Get retrieves the value associated with the given key, invoking backend where necessary.
+func (c *Encoded[K, V]) Get(key K) (value V, err error) {
+	data, err := c.cache.Get(key)
+
+	if err != nil {
+		return
+	}
+
+	return c.decode(data)
+}
+
+// Delete evicts the given key from the cache.
+func (c *Encoded[K, V]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+// Len returns the current number of items in the cache.
+func (c *Encoded[K, V]) Len() int {
+	return c.cache.Len()
+}
+
+// Stats returns statistics of the cache.
+func (c *Encoded[K, V]) Stats() Stats {
+	return c.cache.Stats()
+}
+
+// Close closes the cache (see LRU.Close).
+func (c *Encoded[K, V]) Close() error {
+	return c.cache.Close()
+}
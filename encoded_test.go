@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncoded(t *testing.T) {
+	var calls int
+
+	backend := func(key int) ([]string, error) {
+		calls++
+		return []string{strings.Repeat("x", key), strings.Repeat("y", key)}, nil
+	}
+
+	c := NewEncoded(10, time.Hour, backend, Gzip(JSONValues[[]string](), 9), WithMaxBytes(1000))
+
+	for _, key := range []int{10000, 10000} {
+		value, err := c.Get(key)
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		if len(value) != 2 || len(value[0]) != key || len(value[1]) != key {
+			t.Errorf("unexpected value of %d items", len(value))
+			return
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("unexpected number of backend calls: %d instead of 1", calls)
+		return
+	}
+
+	// the value is stored compressed
+	if s := c.Stats(); s.Size != 1 || s.Weight > 1000 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+
+	// invalid data
+	c.cache.Put(context.Background(), 1, []byte("garbage"))
+
+	if _, err := c.Get(1); err == nil {
+		t.Error("missing error")
+		return
+	}
+}
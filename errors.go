@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is returned from the cache after it has been closed.
+var ErrClosed = errors.New("cache is closed")
+
+// PanicError is the error returned from the cache when the backend function has panicked.
+type PanicError struct {
+	Value any    // value passed to panic
+	Stack []byte // stack trace of the panic
+}
+
+// Error implements error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprint("backend function panicked: ", e.Value)
+}
+
+// Validation errors returned from TryNew, wrapped in ConfigError.
+var (
+	ErrInvalidCapacity = errors.New("invalid capacity")
+	ErrInvalidTTL      = errors.New("invalid TTL")
+	ErrNilBackend      = errors.New("nil backend function")
+	ErrInvalidOption   = errors.New("invalid option")
+)
+
+// ConfigError describes an invalid parameter or option given to a cache constructor.
+// The kind of the error can be checked with errors.Is against one of the validation errors.
+type ConfigError struct {
+	Kind error  // one of the validation errors
+	Msg  string // details
+}
+
+// Error implements error interface.
+func (e *ConfigError) Error() string {
+	return "attempt to create an LRU cache with " + e.Msg
+}
+
+// Unwrap returns the kind of the error.
+func (e *ConfigError) Unwrap() error {
+	return e.Kind
+}
+
+// make a new configuration error
+func configError(kind error, msg string) error {
+	return &ConfigError{kind, msg}
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTryNew(t *testing.T) {
+	tests := []struct {
+		size    int
+		ttl     time.Duration
+		backend func(int) (int, error)
+		opts    []Option
+		kind    error
+	}{
+		{0, time.Hour, simpleBackend, nil, ErrInvalidCapacity},
+		{10, -time.Hour, simpleBackend, nil, ErrInvalidTTL},
+		{10, time.Hour, nil, nil, ErrNilBackend},
+		{10, time.Hour, simpleBackend, []Option{WithTTLJitter(2)}, ErrInvalidOption},
+		{10, time.Hour, simpleBackend, []Option{WithOnEvict(func(string, int, EvictReason) {})}, ErrInvalidOption},
+		{10, time.Hour, simpleBackend, []Option{WithEvents(-1)}, ErrInvalidOption},
+		{10, time.Hour, simpleBackend, []Option{WithOrderedIndex[string]()}, ErrInvalidOption},
+	}
+
+	for i, test := range tests {
+		c, err := TryNew(test.size, test.ttl, test.backend, test.opts...)
+
+		if c != nil || !errors.Is(err, test.kind) {
+			t.Errorf("[%d] unexpected result: %v", i, err)
+			return
+		}
+
+		var cerr *ConfigError
+
+		if !errors.As(err, &cerr) {
+			t.Errorf("[%d] unexpected error type: %T", i, err)
+			return
+		}
+	}
+
+	c, err := TryNew(10, time.Hour, simpleBackend)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err = getOne(c, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the constructor still panics with the same message
+	defer func() {
+		if p := recover(); p != "attempt to create an LRU cache with invalid capacity of 0 items" {
+			t.Error("unexpected panic:", p)
+		}
+	}()
+
+	New(0, time.Hour, simpleBackend)
+}
@@ -0,0 +1,91 @@
+package cache
+
+import "time"
+
+// EventKind is the type of a cache lifecycle event.
+type EventKind int
+
+const (
+	// EventLoadStarted is emitted before calling the backend for a key.
+	EventLoadStarted EventKind = iota + 1
+
+	// EventLoadFinished is emitted after the backend has returned, with the error, if any.
+	EventLoadFinished
+
+	// EventEvicted is emitted when an item is evicted for capacity or replaced by a refresh.
+	EventEvicted
+
+	// EventExpired is emitted when an expired item is removed from the cache.
+	EventExpired
+
+	// EventDeleted is emitted when an item is deleted explicitly.
+	EventDeleted
+)
+
+// String returns the name of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventLoadStarted:
+		return "load-started"
+	case EventLoadFinished:
+		return "load-finished"
+	case EventEvicted:
+		return "evicted"
+	case EventExpired:
+		return "expired"
+	case EventDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes something that has happened to a key in the cache.
+type Event[K comparable] struct {
+	Kind   EventKind   // type of the event
+	Key    K           // key
+	Reason EvictReason // reason for removal (for EventEvicted, EventExpired and EventDeleted)
+	Err    error       // backend error (for EventLoadFinished)
+	Time   time.Time   // time of the event
+}
+
+// Events returns the channel of cache lifecycle events, or nil if the cache has been
+// created without WithEvents option. The channel is bounded: when it is full, the oldest
+// event is discarded to make room for a new one, so a slow consumer never blocks the cache.
+// The channel is never closed.
+func (c *LRU[K, V]) Events() <-chan Event[K] {
+	return c.events
+}
+
+// send the event to the channel, discarding the oldest one if the channel is full
+func (c *LRU[K, V]) emit(kind EventKind, key K, reason EvictReason, err error) {
+	if c.events == nil {
+		return
+	}
+
+	e := Event[K]{Kind: kind, Key: key, Reason: reason, Err: err, Time: c.now()}
+
+	for {
+		select {
+		case c.events <- e:
+			return
+		default:
+			select {
+			case <-c.events:
+			default:
+			}
+		}
+	}
+}
+
+// emit the event corresponding to the eviction reason
+func (c *LRU[K, V]) emitEviction(key K, reason EvictReason) {
+	switch reason {
+	case EvictCapacity, EvictReplaced:
+		c.emit(EventEvicted, key, reason, nil)
+	case EvictExpired:
+		c.emit(EventExpired, key, reason, nil)
+	case EvictDeleted:
+		c.emit(EventDeleted, key, reason, nil)
+	}
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	c := NewWithTTL(2, time.Hour, func(key int) (int, time.Duration, error) {
+		v, err := simpleBackend(key)
+
+		if key == 5 {
+			return v, time.Nanosecond, err
+		}
+
+		return v, 0, err
+	}, WithEvents(100))
+
+	if err := fill(c.Get, []int{1, 1000, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.Delete(2)
+
+	if err := fill(c.Get, []int{5, 5}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	exp := []string{
+		"load-started:1:<nil>",
+		"load-finished:1:<nil>",
+		"load-started:1000:<nil>",
+		"load-finished:1000:key not found: 1000",
+		"evicted:1:<nil>",
+		"load-started:2:<nil>",
+		"load-finished:2:<nil>",
+		"deleted:2:<nil>",
+		"load-started:5:<nil>",
+		"load-finished:5:<nil>",
+		"expired:5:<nil>",
+		"load-started:5:<nil>",
+		"load-finished:5:<nil>",
+	}
+
+	if err := matchLogs(drainEvents(c.Events()), exp); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestEventsDropOldest(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend, WithEvents(2))
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	exp := []string{"load-started:2:<nil>", "load-finished:2:<nil>"}
+
+	if err := matchLogs(drainEvents(c.Events()), exp); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if New(10, time.Hour, simpleBackend).Events() != nil {
+		t.Error("unexpected event channel")
+		return
+	}
+}
+
+func drainEvents[K comparable](events <-chan Event[K]) (log []string) {
+	for {
+		select {
+		case e := <-events:
+			log = append(log, fmt.Sprintf("%s:%v:%v", e.Kind, e.Key, e.Err))
+		default:
+			return
+		}
+	}
+}
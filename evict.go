@@ -0,0 +1,76 @@
+package cache
+
+// EvictReason describes why an item has left the cache.
+type EvictReason int
+
+const (
+	evictNone EvictReason = iota // not evicted
+
+	// EvictCapacity means the item has been evicted to make room for another one.
+	EvictCapacity
+
+	// EvictExpired means the item has expired.
+	EvictExpired
+
+	// EvictDeleted means the item has been deleted explicitly.
+	EvictDeleted
+
+	// EvictReplaced means the item has been replaced with a freshly fetched one.
+	EvictReplaced
+
+	// EvictClosed means the item has been released by closing the cache.
+	EvictClosed
+
+	numEvictReasons // number of eviction reasons, including evictNone
+)
+
+// String returns the name of the reason.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	case EvictReplaced:
+		return "replaced"
+	case EvictClosed:
+		return "closed"
+	default:
+		return "none"
+	}
+}
+
+// eviction record
+type eviction[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// count the eviction (the cache must be locked)
+func (c *LRU[K, V]) count(reason EvictReason) {
+	if reason != evictNone {
+		c.stats.evictions[reason].Add(1)
+	}
+}
+
+// queue the eviction of the node to be reported after unlocking the cache (the cache must be locked)
+func (c *LRU[K, V]) notify(node *lruNode[K, V], reason EvictReason) {
+	if c.onEvict != nil && reason != evictNone {
+		c.evicted = append(c.evicted, eviction[K, V]{node.key, node.value, reason})
+	}
+}
+
+// unlock the cache and report the evictions, if any
+func (c *LRU[K, V]) unlock() {
+	evicted := c.evicted
+
+	c.evicted = nil
+	c.mu.Unlock()
+
+	for _, e := range evicted {
+		c.onEvict(e.key, e.value, e.reason)
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnEvict(t *testing.T) {
+	var log []string
+
+	c := NewWithTTL(3, time.Hour, func(key int) (int, time.Duration, error) {
+		v, err := simpleBackend(key)
+
+		if key == 5 {
+			return v, time.Nanosecond, err
+		}
+
+		return v, 0, err
+	}, WithOnEvict(func(k, v int, reason EvictReason) {
+		log = append(log, fmt.Sprintf("%d:%d:%s", k, v, reason))
+	}))
+
+	// capacity eviction, with errors not reported
+	if err := fill(c.Get, []int{1000, 1, 2, 3, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// deletion
+	c.Delete(2)
+	c.Delete(2)
+
+	// expiration
+	if err := fill(c.Get, []int{5, 5}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.sweep()
+
+	if err := matchLogs(log, []string{"1:-1:capacity", "2:-2:deleted", "5:-5:expired", "5:-5:expired"}); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestOnEvictWhileLoading(t *testing.T) {
+	var (
+		log []string
+		mu  sync.Mutex
+	)
+
+	started, release := make(chan struct{}), make(chan struct{})
+
+	c := New(2, time.Hour, func(key int) (int, error) {
+		if key == 1 {
+			close(started)
+			<-release
+		}
+
+		return simpleBackend(key)
+	}, WithOnEvict(func(k, v int, reason EvictReason) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		log = append(log, fmt.Sprintf("%d:%d:%s", k, v, reason))
+	}))
+
+	done := make(chan error)
+
+	go func() {
+		done <- getOne(c, 1)
+	}()
+
+	<-started
+
+	c.Delete(1)
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := matchLogs(log, []string{"1:-1:deleted"}); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func matchLogs(got, exp []string) error {
+	if len(got) != len(exp) {
+		return fmt.Errorf("log length mismatch: %q instead of %q", got, exp)
+	}
+
+	for i, s := range got {
+		if s != exp[i] {
+			return fmt.Errorf("log mismatch @ %d: %q instead of %q", i, s, exp[i])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// min-heap of cache nodes ordered by expiration time
+type expiryHeap[K comparable, V any] []*lruNode[K, V]
+
+func (h expiryHeap[K, V]) Len() int {
+	return len(h)
+}
+
+func (h expiryHeap[K, V]) Less(i, j int) bool {
+	return h[i].deadline().Before(h[j].deadline())
+}
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap[K, V]) Push(x any) {
+	node := x.(*lruNode[K, V])
+
+	node.index = len(*h)
+	*h = append(*h, node)
+}
+
+func (h *expiryHeap[K, V]) Pop() any {
+	old := *h
+	n := len(old) - 1
+	node := old[n]
+
+	old[n] = nil // help gc
+	node.index = -1
+	*h = old[:n]
+
+	return node
+}
+
+// expiration time of the node, not counting the expire-after-access timeout
+func (node *lruNode[K, V]) deadline() time.Time {
+	return node.ts.Add(node.lifetime())
+}
+
+// expiration time of the node, including the expire-after-access timeout
+func (c *LRU[K, V]) expires(node *lruNode[K, V]) time.Time {
+	t := node.deadline()
+
+	if c.accessTTL > 0 {
+		if idle := node.atime.Add(c.accessTTL); idle.Before(t) {
+			return idle
+		}
+	}
+
+	return t
+}
+
+// SetTTL changes the expiration time of the item for the given key to the given duration from now,
+// either extending or shortening its lifetime. A non-positive duration makes the item expire
+// immediately, so that the next request reloads it, while NoExpiry retains the item until evicted.
+// The idle timeout (see WithExpireAfterAccess) is not affected. The method returns false if the key
+// is not in the cache, or its data has not been fetched yet.
+func (c *LRU[K, V]) SetTTL(key K, d time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.nodes[key]
+
+	if node == nil || !node.ready || !node.cached() || !c.current(node) {
+		return false
+	}
+
+	if d != NoExpiry {
+		d = max(c.now().Sub(node.ts)+d, 0)
+	}
+
+	node.ttl.Store(int64(d))
+	heap.Fix(&c.expiry, node.index)
+	return true
+}
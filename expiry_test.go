@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestExpiryHeap(t *testing.T) {
+	c := NewWithTTL(50, time.Hour, func(key int) (int, time.Duration, error) {
+		v, err := simpleBackend(key)
+
+		return v, time.Duration(rand.Intn(1000)+1) * time.Minute, err
+	})
+
+	for i := 0; i < 10000; i++ {
+		k := rand.Intn(100)
+
+		if rand.Intn(10) == 0 {
+			c.Delete(k)
+		} else if err := getOne(c, k); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if err := checkExpiryHeap(c); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+// validate the expiry heap invariants
+func checkExpiryHeap(c *LRU[int, int]) error {
+	if len(c.expiry) != len(c.nodes) {
+		return fmt.Errorf("expiry heap size mismatch: %d instead of %d", len(c.expiry), len(c.nodes))
+	}
+
+	for i, node := range c.expiry {
+		if node.index != i {
+			return fmt.Errorf("invalid heap index of key %d: %d instead of %d", node.key, node.index, i)
+		}
+
+		if c.nodes[node.key] != node {
+			return fmt.Errorf("heap node with key %d is not in the cache", node.key)
+		}
+
+		if i > 0 && node.deadline().Before(c.expiry[(i-1)/2].deadline()) {
+			return fmt.Errorf("heap order violation at index %d", i)
+		}
+	}
+
+	return nil
+}
+
+func TestSetTTL(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := now
+
+	var b tracingBackend
+
+	c := New(10, time.Hour, b.fn, WithClock(func() time.Time { return now }))
+
+	if c.SetTTL(1, time.Minute) {
+		t.Error("unexpected TTL update for a missing key")
+		return
+	}
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	now = now.Add(30 * time.Minute)
+
+	// extend
+	if !c.SetTTL(1, 2*time.Hour) {
+		t.Error("failed to update TTL")
+		return
+	}
+
+	if expires, found := c.ExpiresAt(1); !found || !expires.Equal(start.Add(150*time.Minute)) {
+		t.Error("unexpected expiration time:", expires, found)
+		return
+	}
+
+	// shorten
+	if !c.SetTTL(2, 0) {
+		t.Error("failed to update TTL")
+		return
+	}
+
+	if err := checkExpiryHeap(c); err != nil {
+		t.Error(err)
+		return
+	}
+
+	now = now.Add(time.Hour)
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := matchTraces(b.trace, []int{1, 2, 2}); err != nil {
+		t.Error(err)
+		return
+	}
+}
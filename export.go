@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"unsafe"
+)
+
+// number of records imported under one lock of the cache
+const importBatch = 256
+
+// Export writes all the valid (i.e., successfully fetched and not expired) items of the cache
+// to the given writer, encoded using the given codec, in the same format as SnapshotWith, but
+// from the least to the most recently used item, so that Import can add the items to a cache
+// one by one, as they are read. Unlike SnapshotWith, the method does not copy the items: the
+// cache is locked only while collecting references to them, and then each item is encoded
+// and written out individually, so that even a large cache can be persisted without building
+// its complete snapshot in memory first.
+func (c *LRU[K, V]) Export(w io.Writer, codec Codec[K, V]) (err error) {
+	nodes := c.acquireValid()
+
+	defer func() {
+		for _, node := range nodes {
+			c.release(node)
+		}
+	}()
+
+	out := bufio.NewWriter(w)
+	now := c.now()
+
+	var buff []byte
+
+	for len(nodes) > 0 {
+		node := nodes[len(nodes)-1]
+		rec := Entry[K, V]{node.key, node.value, node.ts, node.deadline()}
+
+		if now.Before(rec.Expires) {
+			if buff, err = writeRecord(out, codec, &rec, buff); err != nil {
+				return
+			}
+		}
+
+		c.release(node)
+		nodes = nodes[:len(nodes)-1]
+	}
+
+	return out.Flush()
+}
+
+// Import reads the items written by Export from the given reader, decoding them using
+// the given codec, and adds them to the cache in small batches, as they are read, skipping
+// the items that have expired since, or are already in the cache. The items keep their
+// original timestamps, expiration times, and LRU order. In case of an error, the items read
+// before the error stay in the cache.
+func (c *LRU[K, V]) Import(r io.Reader, codec Codec[K, V]) error {
+	batch := make([]Entry[K, V], 0, importBatch)
+
+	// the batch goes to restore in reverse order, the most recent item first
+	flush := func() {
+		slices.Reverse(batch)
+		c.restore(batch)
+		batch = batch[:0]
+	}
+
+	defer flush()
+
+	return readRecords(r, codec, func(rec *Entry[K, V]) {
+		if batch = append(batch, *rec); len(batch) == importBatch {
+			flush()
+		}
+	})
+}
+
+// acquire the valid nodes in LRU order, most recent first
+func (c *LRU[K, V]) acquireValid() (nodes []*lruNode[K, V]) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	nodes = make([]*lruNode[K, V], 0, len(c.nodes))
+
+	for _, root := range c.lists() {
+		for p := root.next; p != root; p = p.next {
+			node := (*lruNode[K, V])(unsafe.Pointer(p))
+
+			// the data fields are only safe to read once the node is ready
+			if node.ready && node.err == nil && c.current(node) {
+				c.acquire(node)
+				nodes = append(nodes, node)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	const n = 2*importBatch + 10
+
+	backend := func(key int) (int, error) {
+		if key < 0 {
+			return 0, fmt.Errorf("invalid key %d", key)
+		}
+
+		return key * 2, nil
+	}
+
+	c := New(n+10, time.Hour, backend)
+
+	for i := -5; i < n; i++ {
+		c.Get(i)
+	}
+
+	c.Get(0)
+
+	var buff bytes.Buffer
+
+	if err := c.Export(&buff, JSONCodec[int, int]{}); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	c2 := New(n+10, time.Hour, func(key int) (int, error) {
+		return 0, fmt.Errorf("unexpected backend call for key %d", key)
+	})
+
+	if err := c2.Import(&buff, JSONCodec[int, int]{}); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// the same items in the same order, without errors
+	if keys, exp := c2.OrderedKeys(), c.OrderedKeys(); !slices.Equal(keys, exp) || len(keys) != n {
+		t.Errorf("unexpected keys: %v instead of %v", keys, exp)
+		return
+	}
+
+	for i := range n {
+		if v, err := c2.Get(i); err != nil || v != i*2 {
+			t.Errorf("unexpected result for key %d: %d, %v", i, v, err)
+			return
+		}
+	}
+
+	// truncated stream
+	buff.Reset()
+
+	if err := c.Export(&buff, JSONCodec[int, int]{}); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	c3 := New(n+10, time.Hour, backend)
+
+	if err := c3.Import(bytes.NewReader(buff.Bytes()[:buff.Len()-1]), JSONCodec[int, int]{}); err == nil {
+		t.Error("missing error")
+		return
+	}
+
+	// the items read before the error are imported
+	if size := c3.Len(); size != n-1 {
+		t.Error("unexpected number of items:", size)
+		return
+	}
+}
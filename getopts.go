@@ -0,0 +1,86 @@
+package cache
+
+import "time"
+
+// GetOption is a function that configures a single request to the cache (see GetWithOptions).
+type GetOption func(*getConfig)
+
+// WithMaxAge makes the request treat the items older than the given age as expired, so that
+// they get reloaded from the backend, and replaced in the cache.
+func WithMaxAge(d time.Duration) GetOption {
+	return func(cfg *getConfig) {
+		cfg.maxAge = d
+	}
+}
+
+// WithSkipCache makes the request always invoke the backend, replacing the item in the cache
+// with the result, unless the result is an error.
+func WithSkipCache() GetOption {
+	return func(cfg *getConfig) {
+		cfg.skipCache = true
+	}
+}
+
+// WithNoStore makes the request not store the value fetched from the backend in the cache.
+// A valid item already in the cache is still returned.
+func WithNoStore() GetOption {
+	return func(cfg *getConfig) {
+		cfg.noStore = true
+	}
+}
+
+// request configuration
+type getConfig struct {
+	maxAge    time.Duration // max. age of the item (0 if unlimited)
+	skipCache bool          // always invoke the backend
+	noStore   bool          // do not store the fetched value
+}
+
+// GetWithOptions is the same as Get, but with the given options applied to this request only,
+// for the code paths that have different requirements for freshness of the data. The backend
+// calls bypassing the cache are not deduplicated with concurrent requests for the same key.
+func (c *LRU[K, V]) GetWithOptions(key K, opts ...GetOption) (V, error) {
+	var cfg getConfig
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if !cfg.skipCache && c.usable(key, &cfg) {
+		return c.Get(key)
+	}
+
+	// fetch bypassing the cache
+	c.stats.misses.Add(1)
+
+	value, ttl, err := c.fetch(key, c.backend)
+
+	if err == nil && !cfg.noStore {
+		err = c.set(key, value, ttl, false)
+	}
+
+	return value, err
+}
+
+// check if the request can be served by Get, given its options
+func (c *LRU[K, V]) usable(key K, cfg *getConfig) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.nodes[key]
+
+	switch {
+	case c.closed:
+		return true // let Get return the error
+	case node == nil:
+		return !cfg.noStore
+	}
+
+	now := c.now()
+
+	if cfg.maxAge > 0 && now.Sub(node.ts) >= cfg.maxAge {
+		return false
+	}
+
+	return !cfg.noStore || c.fresh(node, now)
+}
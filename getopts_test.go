@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetOptions(t *testing.T) {
+	var backend tracingBackend
+
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := New(10, time.Hour, backend.fn, WithClock(func() time.Time { return now }))
+
+	get := func(key int, opts ...GetOption) error {
+		v, err := c.GetWithOptions(key, opts...)
+
+		if err != nil {
+			return err
+		}
+
+		if v != -key {
+			t.Errorf("value mismatch for key %d: %d instead of %d", key, v, -key)
+		}
+
+		return nil
+	}
+
+	steps := []struct {
+		key   int
+		opts  []GetOption
+		trace []int
+	}{
+		{1, nil, []int{1}},
+		{1, []GetOption{WithMaxAge(time.Minute)}, []int{1}},
+		{1, []GetOption{WithSkipCache()}, []int{1, 1}},
+		{2, []GetOption{WithNoStore()}, []int{1, 1, 2}},
+		{2, nil, []int{1, 1, 2, 2}},
+		{2, []GetOption{WithNoStore()}, []int{1, 1, 2, 2}},
+		{3, []GetOption{WithSkipCache(), WithNoStore()}, []int{1, 1, 2, 2, 3}},
+		{3, []GetOption{WithNoStore()}, []int{1, 1, 2, 2, 3, 3}},
+	}
+
+	for i, step := range steps {
+		if err := get(step.key, step.opts...); err != nil {
+			t.Errorf("step %d: unexpected error: %s", i, err)
+			return
+		}
+
+		if err := matchTraces(backend.trace, step.trace); err != nil {
+			t.Errorf("step %d: invalid trace: %s", i, err)
+			return
+		}
+	}
+
+	// max. age
+	now = now.Add(2 * time.Minute)
+
+	for _, key := range []int{1, 1, 2} {
+		if err := get(key, WithMaxAge(time.Minute)); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 1, 2, 2, 3, 3, 1, 2}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if n := len(c.nodes); n != 2 {
+		t.Errorf("unexpected number of items: %d instead of 2", n)
+		return
+	}
+}
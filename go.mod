@@ -1,3 +1,3 @@
 module github.com/maxim2266/cache
 
-go 1.19
+go 1.24
@@ -0,0 +1,70 @@
+package cache
+
+import "unsafe"
+
+// number of the least recent items to choose the victim from under GreedyDual policy
+const greedyDualSample = 8
+
+// WithCost sets the function to calculate the cost of fetching each item again, for example,
+// from the size of the value, to be used by PolicyGreedyDual, where by default each item costs 1.
+// The function is invoked with the cache locked, so it must be fast and must not access
+// the cache. Errors have zero cost. The key and value types of the function must match those
+// of the cache.
+func WithCost[K comparable, V any](fn func(K, V) float64) Option {
+	return func(cfg *config) {
+		cfg.cost = fn
+	}
+}
+
+// WithLatencyCost makes PolicyGreedyDual use the time taken to fetch each item from the backend
+// as its cost, in seconds, so that the items slow to load are kept in the cache longer than
+// the fast ones. The items added to the cache directly (see Set) have zero cost. The option
+// cannot be combined with WithCost.
+func WithLatencyCost() Option {
+	return func(cfg *config) {
+		cfg.latencyCost = true
+	}
+}
+
+// set the cost and the priority of the node with its data fetched (the cache must be locked)
+func (c *LRU[K, V]) prioritise(node *lruNode[K, V]) {
+	if c.policy != PolicyGreedyDual {
+		return
+	}
+
+	var cost float64
+
+	switch {
+	case node.err != nil:
+		// zero cost
+	case c.latencyCost:
+		cost = node.latency.Seconds()
+	case c.coster != nil:
+		cost = max(c.coster(node.key, node.value), 0)
+	default:
+		cost = 1
+	}
+
+	node.cost = cost / float64(max(node.weight, 1))
+	node.prio = c.inflation + node.cost
+}
+
+// find the node with the lowest priority among the least recent ones, inflating the priorities
+// of all the other nodes by its priority, or nil if all the nodes are pinned
+func (c *LRU[K, V]) cheapest() (victim *lruNode[K, V]) {
+	p := c.list.prev
+
+	for i := 0; i < greedyDualSample && p != &c.list; i++ {
+		if node := (*lruNode[K, V])(unsafe.Pointer(p)); victim == nil || node.prio < victim.prio {
+			victim = node
+		}
+
+		p = p.prev
+	}
+
+	if victim != nil {
+		c.inflation = max(c.inflation, victim.prio)
+	}
+
+	return
+}
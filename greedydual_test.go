@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGreedyDual(t *testing.T) {
+	c := New(3, time.Hour, simpleBackend,
+		WithPolicy(PolicyGreedyDual),
+		WithCost(func(k, _ int) float64 { return float64(k) }))
+
+	// the cheapest items get evicted first, regardless of their recency
+	if err := fill(c.Get, []int{10, 1, 20, 30}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{10, 20, 30}, validKey); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the priority of the evicted item is added to the priorities of the new ones
+	if c.inflation != 1 || c.nodes[30].prio != 31 {
+		t.Error("unexpected priorities:", c.inflation, c.nodes[30].prio)
+		return
+	}
+
+	// key 10 is evicted, then key 2 gets the priority of 12, which is still the lowest
+	if err := fill(c.Get, []int{2, 40}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{20, 30, 40}, validKey); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// a hit restores the priority of the item
+	if err := fill(c.Get, []int{20}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if p := c.nodes[20].prio; p != 32 {
+		t.Error("unexpected priority after hit:", p)
+		return
+	}
+}
+
+func TestGreedyDualSize(t *testing.T) {
+	// uniform cost, so the heaviest items get evicted first
+	c := New(3, time.Hour, simpleBackend,
+		WithPolicy(PolicyGreedyDual),
+		WithWeigher(1000, func(k, _ int) int { return k }))
+
+	if err := fill(c.Get, []int{1, 50, 2, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{1, 2, 3}, validKey); err != nil {
+		t.Error(err)
+		return
+	}
+}
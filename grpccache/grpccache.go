@@ -0,0 +1,143 @@
+// Package grpccache provides gRPC client interceptor caching responses of unary calls.
+package grpccache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/maxim2266/cache"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// KeyFunc maps a request for the given method to the cache key, returning false if the
+// response is not to be cached (e.g., because the method is not idempotent).
+type KeyFunc func(method string, req any) (string, bool)
+
+// Interceptor is an opaque type representing a cache of responses from unary gRPC calls.
+type Interceptor struct {
+	cache *cache.LRU[string, proto.Message] // cached responses
+	keyFn KeyFunc                           // cache key function
+}
+
+// New creates a new gRPC response cache, using the given function to select the calls to cache
+// and build their keys. The size, TTL and options apply to the underlying cache, but the calls
+// are only made on behalf of the callers, so background reloads (see cache.WithRefreshAhead)
+// always fail. Errors are never cached.
+func New(size int, ttl time.Duration, keyFn KeyFunc, opts ...cache.Option) *Interceptor {
+	if keyFn == nil {
+		panic("attempt to create a gRPC cache with nil key function")
+	}
+
+	i := &Interceptor{keyFn: keyFn}
+
+	opts = append(opts, cache.WithErrorPolicy(func(error) time.Duration { return 0 }))
+	i.cache = cache.New(size, ttl, func(string) (proto.Message, error) {
+		return nil, errNoCall
+	}, opts...)
+
+	return i
+}
+
+// NoCache returns a call option that makes the interceptor bypass the cache for the call.
+func NoCache() grpc.CallOption {
+	return noCache{}
+}
+
+type noCache struct {
+	grpc.EmptyCallOption
+}
+
+// Unary returns the client interceptor serving the responses from the cache. On a cache miss,
+// the call proceeds with the context and call options of the caller that has started loading
+// the key, while the concurrent callers for the same key wait for its result; if the call fails
+// because that caller's context is done, the other callers retry with their own contexts. The call
+// options that capture response metadata (e.g., grpc.Header) have no effect on cache hits.
+func (i *Interceptor) Unary() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		msg, ok := reply.(proto.Message)
+
+		if !ok || bypass(opts) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		k, ok := i.keyFn(method, req)
+
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		resp, err := i.cache.GetWith(cacheKey(method, k), func(string) (proto.Message, error) {
+			reply := msg.ProtoReflect().New().Interface()
+
+			if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+				return nil, err
+			}
+
+			return reply, nil
+		})
+
+		switch {
+		case err == nil:
+			proto.Reset(msg)
+			proto.Merge(msg, resp)
+			return nil
+
+		case ctx.Err() == nil && aborted(err):
+			// the load was aborted by another caller's context
+			return invoker(ctx, method, req, reply, cc, opts...)
+
+		default:
+			return err
+		}
+	}
+}
+
+// Invalidate evicts the cached response for the given method and request, if any.
+func (i *Interceptor) Invalidate(method string, req any) {
+	if k, ok := i.keyFn(method, req); ok {
+		i.cache.Delete(cacheKey(method, k))
+	}
+}
+
+// Stats returns statistics of the underlying cache.
+func (i *Interceptor) Stats() cache.Stats {
+	return i.cache.Stats()
+}
+
+// Close closes the underlying cache (see LRU.Close), returning its error, if any.
+func (i *Interceptor) Close() error {
+	return i.cache.Close()
+}
+
+// error from the backend of the cache, which has no call to make
+var errNoCall = errors.New("no call to load the response")
+
+// cache key for the method and the user key
+func cacheKey(method, key string) string {
+	return method + "\x00" + key
+}
+
+// check if the call options disable the cache
+func bypass(opts []grpc.CallOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(noCache); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if the error is due to a cancelled or expired context
+func aborted(err error) bool {
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return true
+	default:
+		return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,196 @@
+package grpccache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestInterceptor(t *testing.T) {
+	var calls int
+
+	invoker := func(_ context.Context, method string, req, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		calls++
+
+		s := req.(*wrapperspb.StringValue).Value
+
+		if s == "fail" {
+			return status.Error(codes.Unavailable, "failure")
+		}
+
+		reply.(*wrapperspb.StringValue).Value = strings.ToUpper(s)
+		return nil
+	}
+
+	i := New(10, time.Hour, func(method string, req any) (string, bool) {
+		return req.(*wrapperspb.StringValue).Value, method == "/test/Get"
+	})
+
+	defer i.Close()
+
+	intercept := i.Unary()
+
+	tests := []struct {
+		method, req, reply string
+		calls              int
+		opts               []grpc.CallOption
+	}{
+		{"/test/Get", "abc", "ABC", 1, nil},
+		{"/test/Get", "abc", "ABC", 1, nil},
+		{"/test/Get", "xyz", "XYZ", 2, nil},
+		{"/test/Get", "abc", "ABC", 3, []grpc.CallOption{NoCache()}},
+		{"/test/Put", "abc", "ABC", 4, nil},
+		{"/test/Put", "abc", "ABC", 5, nil},
+		{"/test/Get", "fail", "", 6, nil},
+		{"/test/Get", "fail", "", 7, nil},
+	}
+
+	for n, test := range tests {
+		reply := wrapperspb.String("garbage")
+		err := intercept(context.Background(), test.method, wrapperspb.String(test.req), reply, nil, invoker, test.opts...)
+
+		if test.reply == "" {
+			if status.Code(err) != codes.Unavailable {
+				t.Errorf("[%d] unexpected error: %v", n, err)
+				return
+			}
+		} else if err != nil || reply.Value != test.reply {
+			t.Errorf("[%d] unexpected result: %q, %v", n, reply.Value, err)
+			return
+		}
+
+		if calls != test.calls {
+			t.Errorf("[%d] unexpected number of calls: %d instead of %d", n, calls, test.calls)
+			return
+		}
+	}
+
+	// invalidation
+	i.Invalidate("/test/Get", wrapperspb.String("abc"))
+
+	if err := intercept(context.Background(), "/test/Get", wrapperspb.String("abc"), new(wrapperspb.StringValue), nil, invoker); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if calls != 8 {
+		t.Errorf("unexpected number of calls: %d instead of 8", calls)
+		return
+	}
+}
+
+func TestInterceptorCancelled(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+
+	invoker := func(ctx context.Context, _ string, req, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+
+		if first {
+			close(started)
+			<-release
+			return status.FromContextError(ctx.Err()).Err()
+		}
+
+		reply.(*wrapperspb.StringValue).Value = "ok"
+		return nil
+	}
+
+	i := New(10, time.Hour, func(_ string, req any) (string, bool) { return "key", true })
+
+	defer i.Close()
+
+	intercept := i.Unary()
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+
+	go func() {
+		errch <- intercept(ctx, "/test/Get", wrapperspb.String("a"), new(wrapperspb.StringValue), nil, invoker)
+	}()
+
+	<-started
+
+	// the second caller waits for the first one, whose context gets cancelled
+	reply := new(wrapperspb.StringValue)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		close(release)
+	}()
+
+	if err := intercept(context.Background(), "/test/Get", wrapperspb.String("a"), reply, nil, invoker); err != nil || reply.Value != "ok" {
+		t.Error("unexpected result:", reply.Value, err)
+		return
+	}
+
+	if err := <-errch; status.Code(err) != codes.Canceled {
+		t.Error("unexpected error:", err)
+		return
+	}
+}
+
+func TestInterceptorConcurrent(t *testing.T) {
+	invoker := func(_ context.Context, _ string, req, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		reply.(*wrapperspb.StringValue).Value = strings.ToUpper(req.(*wrapperspb.StringValue).Value)
+		return nil
+	}
+
+	i := New(1, time.Hour, func(_ string, req any) (string, bool) {
+		return req.(*wrapperspb.StringValue).Value, true
+	})
+
+	defer i.Close()
+
+	intercept := i.Unary()
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 1)
+
+	// many calls for the same few keys, with evictions
+	for n := range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range 200 {
+				req := string(rune('a' + (n+j)%3))
+				reply := new(wrapperspb.StringValue)
+
+				if err := intercept(context.Background(), "/test/Get", wrapperspb.String(req), reply, nil, invoker); err != nil || reply.Value != strings.ToUpper(req) {
+					select {
+					case errs <- fmt.Errorf("unexpected result for %q: %q, %v", req, reply.Value, err):
+					default:
+					}
+
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		t.Error(err)
+	default:
+	}
+}
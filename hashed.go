@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Hashed is an opaque type representing an LRU cache with keys of any type "K", including
+// non-comparable types like slices or maps, and values of type "V".
+type Hashed[K any, V any] struct {
+	cache   *LRU[uint64, V]    // underlying cache, keyed by key id
+	hash    func(K) uint64     // key hash function
+	eq      func(K, K) bool    // key equality function
+	backend func(K) (V, error) // function for fetching data on cache miss
+
+	mu    sync.Mutex            // protects the key mappings
+	slots map[uint64][]uint64   // key ids by hash
+	keys  map[uint64]*hashed[K] // keys by id
+	next  uint64                // last allocated key id
+}
+
+// key with its hash
+type hashed[K any] struct {
+	key  K
+	hash uint64
+	refs int    // number of requests in progress for the key
+	uses uint64 // total number of requests for the key
+}
+
+// NewHashed creates a new LRU cache with keys of type "K" and values of type "V", where the keys
+// are compared using the given hash and equality functions instead of the built-in equality.
+// The options requiring functions of the key type (like WithOnEvict) are not supported.
+func NewHashed[K any, V any](
+	size int,
+	ttl time.Duration,
+	hash func(K) uint64,
+	eq func(K, K) bool,
+	backend func(K) (V, error),
+	opts ...Option,
+) *Hashed[K, V] {
+	if hash == nil || eq == nil {
+		panic("attempt to create an LRU cache with nil hash or equality function")
+	}
+
+	if backend == nil {
+		panic("attempt to create an LRU cache with nil backend function")
+	}
+
+	c := &Hashed[K, V]{
+		hash:    hash,
+		eq:      eq,
+		backend: backend,
+		slots:   make(map[uint64][]uint64),
+		keys:    make(map[uint64]*hashed[K]),
+	}
+
+	// the id of a key is allocated before the load, including a background reload of an item
+	// in the cache, so the key is only missing if it has left the cache in the meantime
+	c.cache = New(size, ttl, func(id uint64) (value V, err error) {
+		key, found := c.key(id)
+
+		if !found {
+			err = ErrNotFound
+			return
+		}
+
+		return c.backend(key)
+	}, opts...)
+
+	c.cache.onDrop = c.forget
+	return c
+}
+
+// Get retrieves the value associated with the given key, invoking backend where necessary.
+func (c *Hashed[K, V]) Get(key K) (V, error) {
+	id := c.acquire(key)
+
+	defer c.release(id)
+
+	return c.cache.Get(id)
+}
+
+// Delete evicts the given key from the cache.
+func (c *Hashed[K, V]) Delete(key K) {
+	if id, found := c.lookup(key, c.hash(key)); found {
+		c.cache.Delete(id)
+	}
+}
+
+// Len returns the current number of items in the cache.
+func (c *Hashed[K, V]) Len() int {
+	return c.cache.Len()
+}
+
+// Stats returns statistics of the cache.
+func (c *Hashed[K, V]) Stats() Stats {
+	return c.cache.Stats()
+}
+
+// Close closes the cache (see LRU.Close), returning its error, if any.
+func (c *Hashed[K, V]) Close() error {
+	return c.cache.Close()
+}
+
+// id of the given key, allocating a new one if necessary; the id must be released after use
+func (c *Hashed[K, V]) acquire(key K) uint64 {
+	h := c.hash(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, found := c.find(key, h)
+
+	if !found {
+		c.next++
+		id = c.next
+		c.slots[h] = append(c.slots[h], id)
+		c.keys[id] = &hashed[K]{key: key, hash: h}
+	}
+
+	k := c.keys[id]
+
+	k.refs++
+	k.uses++
+
+	return id
+}
+
+// release the id after use, forgetting it if the key has not got into the cache (e.g., because
+// of a backend error)
+func (c *Hashed[K, V]) release(id uint64) {
+	c.mu.Lock()
+
+	k := c.keys[id]
+	k.refs--
+	idle, uses := k.refs == 0, k.uses
+
+	c.mu.Unlock()
+
+	// the cache is checked without holding the lock on the mappings, as the cache invokes
+	// forget with its own lock held
+	if !idle || c.cached(id) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the key may have been requested again since the check
+	if k.refs == 0 && k.uses == uses {
+		c.remove(id)
+	}
+}
+
+// check if the cache holds the item with the given id
+func (c *Hashed[K, V]) cached(id uint64) bool {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+
+	return c.cache.nodes[id] != nil
+}
+
+// key with the given id, if still allocated
+func (c *Hashed[K, V]) key(id uint64) (key K, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k, found := c.keys[id]
+
+	if found {
+		key = k.key
+	}
+
+	return
+}
+
+// id of the given key, if already allocated
+func (c *Hashed[K, V]) lookup(key K, h uint64) (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.find(key, h)
+}
+
+// find the id of the key (the mappings must be locked)
+func (c *Hashed[K, V]) find(key K, h uint64) (uint64, bool) {
+	for _, id := range c.slots[h] {
+		if c.eq(c.keys[id].key, key) {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+// release the id of a key that has left the cache, unless the key is still being requested
+func (c *Hashed[K, V]) forget(id uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if k := c.keys[id]; k != nil && k.refs == 0 {
+		c.remove(id)
+	}
+}
+
+// delete the mappings of the id, if any (the mappings must be locked)
+func (c *Hashed[K, V]) remove(id uint64) {
+	k, found := c.keys[id]
+
+	if !found {
+		return
+	}
+
+	delete(c.keys, id)
+
+	ids := c.slots[k.hash]
+
+	for i := range ids {
+		if ids[i] == id {
+			ids = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+
+	if len(ids) == 0 {
+		delete(c.slots, k.hash)
+	} else {
+		c.slots[k.hash] = ids
+	}
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHashed(t *testing.T) {
+	var calls []string
+
+	backend := func(path []string) (string, error) {
+		s := strings.Join(path, "/")
+
+		calls = append(calls, s)
+		return s, nil
+	}
+
+	// constant hash makes all the keys collide
+	c := NewHashed(2, time.Hour, func([]string) uint64 { return 0 }, slices.Equal[[]string], backend)
+
+	keys := [][]string{{"a", "b"}, {"a"}, {"a", "b"}, {"c"}, {"a"}}
+
+	for _, key := range keys {
+		v, err := c.Get(key)
+
+		if err != nil || v != strings.Join(key, "/") {
+			t.Error("unexpected result:", v, err)
+			return
+		}
+	}
+
+	// "a" gets evicted by "c", and then "a/b" by "a"
+	if err := matchLogs(calls, []string{"a/b", "a", "c", "a"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(c.keys) != 2 || len(c.slots[0]) != 2 {
+		t.Error("unexpected key mappings:", c.keys, c.slots)
+		return
+	}
+
+	c.Delete([]string{"a"})
+	c.Delete([]string{"x"})
+
+	if len(c.keys) != 1 {
+		t.Error("unexpected key mappings:", c.keys)
+		return
+	}
+
+	c.Close()
+
+	if len(c.keys) != 0 || len(c.slots) != 0 {
+		t.Error("unexpected key mappings after closing:", c.keys, c.slots)
+		return
+	}
+
+	// no ids get allocated after closing
+	if _, err := c.Get([]string{"a"}); err != ErrClosed || len(c.keys) != 0 {
+		t.Error("unexpected result after closing:", err, c.keys)
+		return
+	}
+}
+
+func TestHashedUncached(t *testing.T) {
+	backend := func(key []int) (int, error) {
+		if len(key) == 0 {
+			return 0, errors.New("empty key")
+		}
+
+		return key[0], nil
+	}
+
+	c := NewHashed(10, time.Hour, func(key []int) uint64 { return uint64(len(key)) }, slices.Equal[[]int], backend,
+		WithErrorPolicy(func(error) time.Duration { return 0 }))
+
+	// the ids of the keys not cached are released
+	for i := 0; i < 5; i++ {
+		if _, err := c.Get(nil); err == nil {
+			t.Error("missing error")
+			return
+		}
+	}
+
+	if _, err := c.Get([]int{1}); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if len(c.keys) != 1 || len(c.slots) != 1 {
+		t.Error("unexpected key mappings:", c.keys, c.slots)
+		return
+	}
+}
+
+func TestHashedRefresh(t *testing.T) {
+	var calls atomic.Int32
+
+	backend := func(key []int) (int, error) {
+		return key[0] + int(calls.Add(1)), nil
+	}
+
+	c := NewHashed(10, 100*time.Millisecond, func(key []int) uint64 { return uint64(len(key)) },
+		slices.Equal[[]int], backend, WithRefreshAhead(0.5))
+
+	defer c.Close()
+
+	if v, err := c.Get([]int{10}); err != nil || v != 11 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// the background reload gets the key from its id
+	for ts := time.Now(); time.Since(ts) < 30*time.Millisecond; time.Sleep(time.Millisecond) {
+		v, err := c.Get([]int{10})
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		if v == 12 {
+			return
+		}
+	}
+
+	t.Error("the value has not been refreshed")
+}
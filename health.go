@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnhealthy is returned from the cache instead of invoking the backend while the backend is
+// reported unhealthy by the health check set via WithHealthCheck option. The error is not cached.
+var ErrUnhealthy = errors.New("backend is unhealthy")
+
+// WithHealthCheck starts a background goroutine that probes the backend every given interval
+// by calling the given function, with the context cancelled after the interval. While the last
+// probe has failed, the cache is in the degraded mode: a cache miss fails immediately with
+// ErrUnhealthy instead of invoking the backend (see also WithFallback), and the expired items
+// are served within the bound set via WithStaleWhileRevalidate, without reloading. The cache
+// starts as healthy, and the goroutine is stopped by the Close method of the cache.
+func WithHealthCheck(check func(context.Context) error, interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.health, cfg.probe = check, interval
+	}
+}
+
+// Healthy returns false while the cache is in the degraded mode (see WithHealthCheck).
+func (c *LRU[K, V]) Healthy() bool {
+	return !c.degraded.Load()
+}
+
+// health check goroutine
+func (c *LRU[K, V]) prober(check func(context.Context) error, interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := check(ctx)
+
+			cancel()
+			c.degraded.Store(err != nil)
+		}
+	}
+}
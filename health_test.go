@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var (
+		calls int32
+		down  atomic.Bool
+	)
+
+	backend := func(key int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return -key, nil
+	}
+
+	check := func(context.Context) error {
+		if down.Load() {
+			return errors.New("down")
+		}
+
+		return nil
+	}
+
+	c := New(10, 20*time.Millisecond, backend,
+		WithHealthCheck(check, time.Millisecond),
+		WithStaleWhileRevalidate(time.Hour))
+
+	defer c.Close()
+
+	// wait for the health state to become the given one
+	healthy := func(state bool) error {
+		for ts := time.Now(); time.Since(ts) < time.Second; time.Sleep(time.Millisecond) {
+			if c.Healthy() == state {
+				return nil
+			}
+		}
+
+		return errors.New("health state has not changed")
+	}
+
+	if _, err := c.Get(1); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	down.Store(true)
+
+	if err := healthy(false); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// a miss fails fast
+	if _, err := c.Get(2); err != ErrUnhealthy {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// the expired item is served stale, without reloading
+	time.Sleep(30 * time.Millisecond)
+
+	if v, err := c.Get(1); err != nil || v != -1 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Error("unexpected number of backend calls:", n)
+		return
+	}
+
+	// back to normal, with the error not cached
+	down.Store(false)
+
+	if err := healthy(true); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if v, err := c.Get(2); err != nil || v != -2 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	if s := c.Stats(); s.Loads != 2 || s.LoadErrors != 0 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+}
@@ -199,3 +199,12 @@ func lruNodeList(c *LRU[int, int]) ([]*lruNode[int, int], error) {
 
 	return res, nil
 }
+
+// collect keys from the given list, starting from least recent
+func listKeys(root *listNode) (keys []int) {
+	for p := root.prev; p != root; p = p.prev {
+		keys = append(keys, (*lruNode[int, int])(unsafe.Pointer(p)).key)
+	}
+
+	return
+}
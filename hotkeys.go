@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"container/heap"
+	"slices"
+	"sync"
+)
+
+// KeyCount is a key with its estimated number of requests (see HotKeys).
+type KeyCount[K comparable] struct {
+	Key   K      // key
+	Count uint64 // estimated number of requests, never less than the actual number
+}
+
+// WithHotKeys enables tracking of the most frequently requested keys (see the HotKeys method),
+// using the space-saving algorithm with the given number of counters, so that any key requested
+// more often than 1/n of all the requests is guaranteed to be tracked. The tracking requires
+// a separate lock on every request, however short.
+func WithHotKeys(n int) Option {
+	return func(cfg *config) {
+		cfg.hotKeys = n
+	}
+}
+
+// HotKeys returns up to n most frequently requested keys, most frequent first, or nil if the cache
+// has been created without WithHotKeys option. The counts are estimates, which are more accurate
+// for more frequent keys.
+func (c *LRU[K, V]) HotKeys(n int) []KeyCount[K] {
+	if c.hot == nil || n <= 0 {
+		return nil
+	}
+
+	return c.hot.top(n)
+}
+
+// space-saving tracker of the most frequent keys
+type hotKeys[K comparable] struct {
+	mu    sync.Mutex       // protects the tracker
+	index map[K]*hotKey[K] // counters by key
+	heap  hotKeyHeap[K]    // counters ordered by count, the least first
+	size  int              // max. number of counters
+}
+
+// counter of a key
+type hotKey[K comparable] struct {
+	KeyCount[K]
+	index int // index in the heap
+}
+
+// create a new tracker of the given capacity
+func newHotKeys[K comparable](size int) *hotKeys[K] {
+	return &hotKeys[K]{
+		index: make(map[K]*hotKey[K], size),
+		heap:  make(hotKeyHeap[K], 0, size),
+		size:  size,
+	}
+}
+
+// count a request for the key
+func (h *hotKeys[K]) record(key K) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if k := h.index[key]; k != nil {
+		k.Count++
+		heap.Fix(&h.heap, k.index)
+		return
+	}
+
+	if len(h.heap) < h.size {
+		k := &hotKey[K]{KeyCount: KeyCount[K]{key, 1}}
+
+		h.index[key] = k
+		heap.Push(&h.heap, k)
+		return
+	}
+
+	// replace the least frequent key, inheriting its count
+	k := h.heap[0]
+
+	delete(h.index, k.Key)
+	h.index[key] = k
+
+	k.Key = key
+	k.Count++
+	heap.Fix(&h.heap, 0)
+}
+
+// copy of the n most frequent keys
+func (h *hotKeys[K]) top(n int) []KeyCount[K] {
+	h.mu.Lock()
+
+	res := make([]KeyCount[K], len(h.heap))
+
+	for i, k := range h.heap {
+		res[i] = k.KeyCount
+	}
+
+	h.mu.Unlock()
+
+	slices.SortStableFunc(res, func(a, b KeyCount[K]) int {
+		switch {
+		case a.Count > b.Count:
+			return -1
+		case a.Count < b.Count:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return res[:min(n, len(res))]
+}
+
+// min-heap of key counters
+type hotKeyHeap[K comparable] []*hotKey[K]
+
+func (h hotKeyHeap[K]) Len() int {
+	return len(h)
+}
+
+func (h hotKeyHeap[K]) Less(i, j int) bool {
+	return h[i].Count < h[j].Count
+}
+
+func (h hotKeyHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *hotKeyHeap[K]) Push(x any) {
+	k := x.(*hotKey[K])
+	k.index = len(*h)
+	*h = append(*h, k)
+}
+
+func (h *hotKeyHeap[K]) Pop() any {
+	old := *h
+	n := len(old) - 1
+	k := old[n]
+
+	old[n] = nil // help gc
+	*h = old[:n]
+
+	return k
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHotKeys(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend, WithHotKeys(10))
+
+	// key 1 is requested every other time, key 2 every fourth time
+	var keys []int
+
+	for i := 0; i < 100; i++ {
+		switch {
+		case i%2 == 0:
+			keys = append(keys, 1)
+		case i%4 == 1:
+			keys = append(keys, 2)
+		default:
+			keys = append(keys, 100+i)
+		}
+	}
+
+	if err := fill(c.Get, keys, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	hot := c.HotKeys(2)
+
+	if len(hot) != 2 || hot[0].Key != 1 || hot[1].Key != 2 {
+		t.Error("unexpected hot keys:", hot)
+		return
+	}
+
+	// the counts are never underestimated
+	if hot[0].Count < 50 || hot[1].Count < 25 {
+		t.Error("unexpected counts:", hot)
+		return
+	}
+
+	if n := len(c.HotKeys(20)); n != 10 {
+		t.Errorf("unexpected number of hot keys: %d instead of 10", n)
+		return
+	}
+
+	// not tracked
+	if hot := New(10, time.Hour, simpleBackend).HotKeys(2); hot != nil {
+		t.Error("unexpected hot keys:", fmt.Sprint(hot))
+		return
+	}
+}
@@ -0,0 +1,288 @@
+// Package httpcache provides HTTP middleware caching responses in an LRU cache.
+package httpcache
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxim2266/cache"
+)
+
+// Handler is an http.Handler that serves GET and HEAD requests from the cache, forwarding
+// them to the next handler on cache miss. All other requests are passed to the next handler
+// unchanged.
+type Handler struct {
+	next  http.Handler                  // handler to cache
+	cache *cache.LRU[string, *response] // cached responses
+	ttl   time.Duration                 // default TTL of the responses
+	vary  sync.Map                      // header names from Vary, by method and URL
+}
+
+// New creates a new caching handler in front of the given one. The lifetime of each response
+// in the cache is taken from its Cache-Control header (s-maxage or max-age directive), or is
+// the given TTL if the header does not specify it, where zero TTL means such responses are not
+// cached. Responses with "no-store", "no-cache" or "private" directives, with Set-Cookie header,
+// with "Vary: *", or with a status code other than 200, 203, 204, 300, 301, 404, 405, 410, 414
+// or 501 are never cached, nor shared between concurrent requests for the same URL, and
+// the requests with Authorization header are always forwarded to the next handler.
+// The variants of a response listed in its Vary header are cached separately. The size and
+// options apply to the underlying cache, but the responses are only loaded on behalf of
+// the requests, so background reloads (see cache.WithRefreshAhead) always fail.
+func New(next http.Handler, size int, ttl time.Duration, opts ...cache.Option) *Handler {
+	if next == nil {
+		panic("attempt to create a caching HTTP handler with nil next handler")
+	}
+
+	if ttl < 0 {
+		panic("attempt to create a caching HTTP handler with negative TTL")
+	}
+
+	h := &Handler{next: next, ttl: ttl}
+
+	// responses that cannot be cached are returned as errors, which are never cached
+	opts = append(opts, cache.WithErrorPolicy(func(error) time.Duration { return 0 }))
+
+	// the loader always returns a positive TTL for a cached response, so the default
+	// TTL of the cache is never used
+	h.cache = cache.New(size, time.Hour, func(key string) (*response, error) {
+		return nil, errNoRequest
+	}, opts...)
+
+	return h
+}
+
+// ServeHTTP implements http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get("Authorization") != "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	var loaded bool
+
+	resp, err := h.cache.GetWithLoader(h.key(r), func(string) (*response, time.Duration, error) {
+		loaded = true
+		return h.load(r)
+	})
+
+	if err != nil {
+		var u *uncacheable
+
+		if !errors.As(err, &u) {
+			// should never happen
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// the response to some other request is never given out, as it may be private
+		if !loaded {
+			h.next.ServeHTTP(w, r)
+			return
+		}
+
+		resp = u.resp
+	}
+
+	resp.write(w)
+}
+
+// Close closes the underlying cache (see LRU.Close), returning its error, if any.
+func (h *Handler) Close() error {
+	return h.cache.Close()
+}
+
+// Stats returns statistics of the underlying cache.
+func (h *Handler) Stats() cache.Stats {
+	return h.cache.Stats()
+}
+
+// cache key for the request: method, absolute URL, and the values of the headers from Vary
+func (h *Handler) key(r *http.Request) string {
+	base := baseKey(r)
+
+	names, ok := h.vary.Load(base)
+
+	if !ok {
+		return base
+	}
+
+	var key strings.Builder
+
+	key.WriteString(base)
+
+	for _, name := range names.([]string) {
+		key.WriteString("\n" + name + ": " + strings.Join(r.Header.Values(name), ", "))
+	}
+
+	return key.String()
+}
+
+// method and absolute URL of the request, where the URL of a server request has no scheme
+// and host, so that the responses of different virtual hosts are cached separately
+func baseKey(r *http.Request) string {
+	u := *r.URL
+
+	if u.Host == "" {
+		u.Host = r.Host
+	}
+
+	if u.Scheme == "" {
+		if u.Scheme = "http"; r.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+
+	return r.Method + " " + u.String()
+}
+
+// error from the backend of the cache, which has no request to forward
+var errNoRequest = errors.New("no request to load the response")
+
+// forward the request to the next handler, recording the response
+func (h *Handler) load(r *http.Request) (*response, time.Duration, error) {
+	rec := &response{header: make(http.Header), status: http.StatusOK}
+
+	h.next.ServeHTTP(rec, r)
+
+	// record the headers the response varies by, and do not cache the response
+	// if the key has been built from a different set of headers
+	base := baseKey(r)
+	names := varyNames(rec.header)
+	prev, _ := h.vary.Load(base)
+
+	if prev, _ := prev.([]string); !slices.Equal(names, prev) {
+		if len(names) > 0 {
+			h.vary.Store(base, names)
+		} else {
+			h.vary.Delete(base)
+		}
+
+		return nil, 0, &uncacheable{rec}
+	}
+
+	ttl := lifetime(rec, h.ttl)
+
+	if ttl <= 0 {
+		return nil, 0, &uncacheable{rec}
+	}
+
+	return rec, ttl, nil
+}
+
+// time-to-live of the response, or 0 if the response is not to be cached
+func lifetime(resp *response, ttl time.Duration) time.Duration {
+	switch resp.status {
+	case 200, 203, 204, 300, 301, 404, 405, 410, 414, 501:
+		// ok
+	default:
+		return 0
+	}
+
+	if resp.header.Get("Set-Cookie") != "" || resp.header.Get("Vary") == "*" {
+		return 0
+	}
+
+	maxAge, sMaxAge := -1, -1
+
+	for _, v := range resp.header.Values("Cache-Control") {
+		for d := range strings.SplitSeq(v, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(d), "=")
+
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache", "private":
+				return 0
+			case "max-age":
+				maxAge = seconds(arg)
+			case "s-maxage":
+				sMaxAge = seconds(arg)
+			}
+		}
+	}
+
+	switch {
+	case sMaxAge >= 0:
+		return time.Duration(sMaxAge) * time.Second
+	case maxAge >= 0:
+		return time.Duration(maxAge) * time.Second
+	default:
+		return ttl
+	}
+}
+
+// parse the directive argument, returning 0 if invalid
+func seconds(s string) int {
+	n, err := strconv.Atoi(strings.Trim(s, `"`))
+
+	if err != nil || n < 0 {
+		return 0
+	}
+
+	return n
+}
+
+// canonical names of the headers listed in Vary header, sorted
+func varyNames(header http.Header) (names []string) {
+	for _, v := range header.Values("Vary") {
+		for name := range strings.SplitSeq(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// recorded response
+type response struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+// Header implements http.ResponseWriter interface.
+func (resp *response) Header() http.Header {
+	return resp.header
+}
+
+// WriteHeader implements http.ResponseWriter interface.
+func (resp *response) WriteHeader(status int) {
+	if !resp.wrote {
+		resp.status, resp.wrote = status, true
+	}
+}
+
+// Write implements http.ResponseWriter interface.
+func (resp *response) Write(data []byte) (int, error) {
+	resp.wrote = true
+	return resp.body.Write(data)
+}
+
+// send the response to the client
+func (resp *response) write(w http.ResponseWriter) {
+	header := w.Header()
+
+	for name, values := range resp.header {
+		header[name] = slices.Clone(values)
+	}
+
+	w.WriteHeader(resp.status)
+	w.Write(resp.body.Bytes())
+}
+
+// response that cannot be cached
+type uncacheable struct {
+	resp *response
+}
+
+func (u *uncacheable) Error() string {
+	return "uncacheable response"
+}
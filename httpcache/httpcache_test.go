@@ -0,0 +1,247 @@
+package httpcache
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	var calls int
+
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		switch r.URL.Path {
+		case "/max-age":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Vary", "accept-language")
+		case "/not-found":
+			w.WriteHeader(http.StatusNotFound)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		io.WriteString(w, r.URL.Path+" "+r.Header.Get("Accept-Language")+" "+strconv.Itoa(calls))
+	}), 100, time.Minute)
+
+	defer h.Close()
+
+	tests := []struct {
+		method, path, lang string
+		status             int
+		body               string
+	}{
+		{"GET", "/max-age", "", 200, "/max-age  1"},
+		{"GET", "/max-age", "", 200, "/max-age  1"},
+		{"HEAD", "/max-age", "", 200, "/max-age  2"},
+		{"GET", "/no-store", "", 200, "/no-store  3"},
+		{"GET", "/no-store", "", 200, "/no-store  4"},
+		{"GET", "/default", "", 200, "/default  5"},
+		{"GET", "/default", "", 200, "/default  5"},
+		{"POST", "/default", "", 200, "/default  6"},
+		{"GET", "/not-found", "", 404, "/not-found  7"},
+		{"GET", "/not-found", "", 404, "/not-found  7"},
+		{"GET", "/error", "", 500, "/error  8"},
+		{"GET", "/error", "", 500, "/error  9"},
+
+		// the first response discovers the Vary header, and is not cached
+		{"GET", "/vary", "en", 200, "/vary en 10"},
+		{"GET", "/vary", "en", 200, "/vary en 11"},
+		{"GET", "/vary", "en", 200, "/vary en 11"},
+		{"GET", "/vary", "fr", 200, "/vary fr 12"},
+		{"GET", "/vary", "fr", 200, "/vary fr 12"},
+		{"GET", "/vary", "en", 200, "/vary en 11"},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+
+		if test.lang != "" {
+			r.Header.Set("Accept-Language", test.lang)
+		}
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("[%d] unexpected status: %d instead of %d", i, w.Code, test.status)
+			return
+		}
+
+		// the recorder keeps the body even for HEAD requests
+		if body := w.Body.String(); body != test.body {
+			t.Errorf("[%d] unexpected body: %q instead of %q", i, body, test.body)
+			return
+		}
+	}
+}
+
+func TestHandlerConcurrent(t *testing.T) {
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.Path)
+	}), 1, time.Minute)
+
+	defer h.Close()
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 1)
+
+	// many requests for the same few keys, with evictions
+	for i := range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range 200 {
+				path := "/" + strconv.Itoa((i+j)%3)
+				w := httptest.NewRecorder()
+
+				h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+				if body := w.Body.String(); w.Code != http.StatusOK || body != path {
+					select {
+					case errs <- fmt.Errorf("unexpected response to %s: %d %q", path, w.Code, body):
+					default:
+					}
+
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		t.Error(err)
+	default:
+	}
+}
+
+func TestHandlerPrivate(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+
+		user := r.Header.Get("Cookie")
+
+		w.Header().Set("Set-Cookie", user)
+		io.WriteString(w, "profile of "+user)
+	}), 100, time.Minute)
+
+	defer h.Close()
+
+	var wg sync.WaitGroup
+
+	bodies := make([]string, 2)
+
+	for i, user := range []string{"alice", "bob"} {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			r := httptest.NewRequest("GET", "/profile", nil)
+			w := httptest.NewRecorder()
+
+			r.Header.Set("Cookie", user)
+			h.ServeHTTP(w, r)
+
+			bodies[i] = w.Body.String() + " " + w.Header().Get("Set-Cookie")
+		}()
+
+		if i == 0 {
+			<-started
+		}
+	}
+
+	// the second request joins the load started by the first one
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, user := range []string{"alice", "bob"} {
+		if exp := "profile of " + user + " " + user; bodies[i] != exp {
+			t.Errorf("unexpected response to %s: %q instead of %q", user, bodies[i], exp)
+			return
+		}
+	}
+}
+
+func TestHandlerHosts(t *testing.T) {
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Host+r.URL.Path)
+	}), 100, time.Minute)
+
+	defer h.Close()
+
+	tests := []struct {
+		host, body string
+		tls        bool
+	}{
+		{"a.example", "a.example/page", false},
+		{"b.example", "b.example/page", false},
+		{"a.example", "a.example/page", true},
+		{"a.example", "a.example/page", false},
+	}
+
+	for i, test := range tests {
+		// the URL of a server request has no scheme and host
+		r := httptest.NewRequest("GET", "/page", nil)
+		w := httptest.NewRecorder()
+
+		if r.Host = test.host; test.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+
+		h.ServeHTTP(w, r)
+
+		if body := w.Body.String(); body != test.body {
+			t.Errorf("[%d] unexpected body: %q instead of %q", i, body, test.body)
+			return
+		}
+	}
+
+	if n := h.cache.Len(); n != 3 {
+		t.Errorf("unexpected number of cached responses: %d instead of 3", n)
+		return
+	}
+}
+
+func TestLifetime(t *testing.T) {
+	tests := []struct {
+		header http.Header
+		ttl    time.Duration
+	}{
+		{http.Header{}, time.Minute},
+		{http.Header{"Cache-Control": {"max-age=10"}}, 10 * time.Second},
+		{http.Header{"Cache-Control": {"max-age=10, s-maxage=20"}}, 20 * time.Second},
+		{http.Header{"Cache-Control": {"max-age=0"}}, 0},
+		{http.Header{"Cache-Control": {"max-age=10", "private"}}, 0},
+		{http.Header{"Set-Cookie": {"a=b"}}, 0},
+		{http.Header{"Vary": {"*"}}, 0},
+	}
+
+	for i, test := range tests {
+		if ttl := lifetime(&response{header: test.header, status: 200}, time.Minute); ttl != test.ttl {
+			t.Errorf("[%d] unexpected TTL: %s instead of %s", i, ttl, test.ttl)
+			return
+		}
+	}
+}
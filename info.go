@@ -0,0 +1,86 @@
+package cache
+
+import "time"
+
+// EntryInfo describes an item in the cache.
+type EntryInfo struct {
+	Created      time.Time     // time when the item was added to the cache
+	LastAccess   time.Time     // time of the last cache hit on the item
+	Hits         int64         // number of cache hits on the item
+	LoadDuration time.Duration // time taken to fetch the item from the backend
+	TTL          time.Duration // remaining time-to-live (non-positive if the item has expired)
+	Err          error         // error returned from the backend, if any
+}
+
+// Info returns information about the given key, if it is in the cache and its data has been
+// fetched. The method does not count as an access to the key. With lock-free cache hits
+// (under the CLOCK policy, or with buffered promotion), the last access time is not tracked,
+// and equals the creation time. The same applies to the caches without time-based expiration,
+// where cache hits do not read the clock.
+func (c *LRU[K, V]) Info(key K) (EntryInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.nodes[key]
+
+	if node == nil || !node.ready || !c.current(node) {
+		return EntryInfo{}, false
+	}
+
+	return EntryInfo{
+		Created:      node.ts,
+		LastAccess:   node.atime,
+		Hits:         node.hits.Load(),
+		LoadDuration: node.latency,
+		TTL:          c.expires(node).Sub(c.now()),
+		Err:          node.err,
+	}, true
+}
+
+// ExpiresAt returns the time when the item for the given key expires, including the idle timeout
+// (see WithExpireAfterAccess), if the key is in the cache and its data has been fetched. A time
+// in the past means the item has expired, but has not been removed yet. The method does not count
+// as an access to the key.
+func (c *LRU[K, V]) ExpiresAt(key K) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.nodes[key]
+
+	if node == nil || !node.ready || !c.current(node) {
+		return time.Time{}, false
+	}
+
+	return c.expires(node), true
+}
+
+// TTL returns the remaining time-to-live of the item for the given key (see ExpiresAt),
+// non-positive if the item has expired.
+func (c *LRU[K, V]) TTL(key K) (time.Duration, bool) {
+	t, found := c.ExpiresAt(key)
+
+	if !found {
+		return 0, false
+	}
+
+	return t.Sub(c.now()), true
+}
+
+// GetStale returns the value for the given key, together with the time when it was fetched,
+// if the value is in the cache, even if it has expired, without invoking the backend, for
+// example, to serve whatever data there is during an outage of the backend. Errors are not
+// returned, and neither are the items made invalid by InvalidateAll. The method does not count
+// as an access to the key. Note that the expired items get removed from the cache by the janitor
+// (see WithJanitor), or on access.
+func (c *LRU[K, V]) GetStale(key K) (value V, ts time.Time, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.nodes[key]
+
+	if node == nil || !node.ready || node.err != nil || !c.current(node) {
+		return
+	}
+
+	return node.value, node.ts, true
+}
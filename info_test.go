@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInfo(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := now
+
+	c := New(10, time.Hour, func(key int) (int, error) {
+		now = now.Add(2 * time.Second) // slow backend
+		return simpleBackend(key)
+	}, WithClock(func() time.Time { return now }))
+
+	if _, found := c.Info(1); found {
+		t.Error("unexpected info for a missing key")
+		return
+	}
+
+	if err := fill(c.Get, []int{1, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	now = now.Add(time.Minute)
+
+	if err := fill(c.Get, []int{1, 1}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	now = now.Add(time.Minute)
+
+	info, found := c.Info(1)
+
+	if !found {
+		t.Error("missing info")
+		return
+	}
+
+	exp := EntryInfo{
+		Created:      start,
+		LastAccess:   start.Add(4*time.Second + time.Minute),
+		Hits:         2,
+		LoadDuration: 2 * time.Second,
+		TTL:          time.Hour - 2*time.Minute - 4*time.Second,
+	}
+
+	if info != exp {
+		t.Errorf("unexpected info:\n%+v\ninstead of\n%+v", info, exp)
+		return
+	}
+
+	// errors
+	if info, found = c.Info(1000); !found || info.Err == nil || info.Hits != 0 {
+		t.Errorf("unexpected info for an error: %+v", info)
+		return
+	}
+
+	// expired items
+	now = now.Add(time.Hour)
+
+	if info, found = c.Info(1); !found || info.TTL > 0 {
+		t.Errorf("unexpected info for an expired item: %+v", info)
+		return
+	}
+}
+
+func TestGetStale(t *testing.T) {
+	var backend tracingBackend
+
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := now
+
+	c := New(10, time.Minute, backend.fn, WithClock(func() time.Time { return now }))
+
+	if err := fill(c.Get, []int{1, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	now = now.Add(time.Hour)
+
+	v, ts, found := c.GetStale(1)
+
+	if !found || v != -1 || !ts.Equal(start) {
+		t.Error("unexpected result:", v, ts, found)
+		return
+	}
+
+	// errors and missing keys
+	for _, key := range []int{1000, 2} {
+		if _, _, found := c.GetStale(key); found {
+			t.Error("unexpected value for key", key)
+			return
+		}
+	}
+
+	// no backend calls
+	if err := matchTraces(backend.trace, []int{1, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := now
+
+	c := New(10, time.Hour, simpleBackend,
+		WithExpireAfterAccess(20*time.Minute),
+		WithClock(func() time.Time { return now }))
+
+	if _, found := c.ExpiresAt(1); found {
+		t.Error("unexpected expiration time for a missing key")
+		return
+	}
+
+	if err := fill(c.Get, []int{1}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// the idle timeout comes first
+	if expires, found := c.ExpiresAt(1); !found || !expires.Equal(start.Add(20*time.Minute)) {
+		t.Error("unexpected expiration time:", expires, found)
+		return
+	}
+
+	for range 3 {
+		now = now.Add(15 * time.Minute)
+
+		if err := fill(c.Get, []int{1}, validKey); err != nil {
+			t.Error("error reading the cache:", err)
+			return
+		}
+	}
+
+	// the TTL comes first
+	if ttl, found := c.TTL(1); !found || ttl != 15*time.Minute {
+		t.Error("unexpected TTL:", ttl, found)
+		return
+	}
+
+	now = now.Add(time.Hour)
+
+	if ttl, found := c.TTL(1); !found || ttl != -45*time.Minute {
+		t.Error("unexpected TTL:", ttl, found)
+		return
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+// Interface is the common subset of the methods of the caches in this package, for writing
+// application code (and test doubles) independent of any particular cache type.
+type Interface[K any, V any] interface {
+	// Get retrieves the value associated with the given key, invoking backend where necessary.
+	Get(key K) (V, error)
+
+	// Delete evicts the given key from the cache.
+	Delete(key K)
+
+	// Len returns the current number of items in the cache.
+	Len() int
+
+	// Close closes the cache.
+	Close() error
+}
+
+// compile-time checks
+var (
+	_ Interface[int, int]    = (*LRU[int, int])(nil)
+	_ Interface[int, int]    = (*Sharded[int, int])(nil)
+	_ Interface[int, int]    = (*Hashed[int, int])(nil)
+	_ Interface[int, int]    = (*Encoded[int, int])(nil)
+	_ Interface[int, int]    = tieredAdapter[int, int]{}
+	_ Interface[int, []byte] = (*Bytes[int])(nil)
+)
+
+// AsInterface returns the adapter of the cache to Interface, where the Delete method ignores
+// the error from the store.
+func (c *Tiered[K, V]) AsInterface() Interface[K, V] {
+	return tieredAdapter[K, V]{c}
+}
+
+// adapter of a tiered cache to Interface
+type tieredAdapter[K comparable, V any] struct {
+	*Tiered[K, V]
+}
+
+// Delete evicts the given key from both levels of the cache.
+func (a tieredAdapter[K, V]) Delete(key K) {
+	a.Tiered.Delete(key)
+}
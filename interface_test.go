@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterface(t *testing.T) {
+	caches := map[string]Interface[int, int]{
+		"lru":     New(10, time.Hour, simpleBackend),
+		"sharded": NewSharded(2, 10, time.Hour, simpleBackend),
+		"tiered":  NewTiered(10, time.Hour, newMapStore[int, int](), simpleBackend).AsInterface(),
+		"hashed": NewHashed(10, time.Hour, func(key int) uint64 { return uint64(key) },
+			func(a, b int) bool { return a == b }, simpleBackend),
+	}
+
+	for name, c := range caches {
+		if err := fill(c.Get, []int{1, 2, 3, 1000}, validKey); err != nil {
+			t.Errorf("%s: error filling the cache: %s", name, err)
+			return
+		}
+
+		c.Delete(2)
+
+		if n := c.Len(); n != 3 {
+			t.Errorf("%s: unexpected number of items: %d instead of 3", name, n)
+			return
+		}
+
+		if err := c.Close(); err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package ring implements consistent hashing of keys to a set of nodes.
+package ring
+
+import (
+	"cmp"
+	"hash/crc32"
+	"slices"
+	"strconv"
+)
+
+// number of points on the ring per node
+const numPoints = 160
+
+// Ring maps keys to nodes using consistent hashing, so that adding or removing a node
+// only remaps the keys of that node.
+type Ring struct {
+	points []point // ring points, ordered by hash
+}
+
+// point on the ring
+type point struct {
+	hash uint32
+	node int
+}
+
+// New creates a new ring over the nodes with the given names.
+func New(names ...string) *Ring {
+	r := &Ring{points: make([]point, 0, len(names)*numPoints)}
+
+	for i, name := range names {
+		for j := range numPoints {
+			r.points = append(r.points, point{crc32.ChecksumIEEE([]byte(name + "-" + strconv.Itoa(j))), i})
+		}
+	}
+
+	slices.SortFunc(r.points, func(a, b point) int { return cmp.Compare(a.hash, b.hash) })
+	return r
+}
+
+// Pick returns the index of the node owning the given key, or -1 if the ring is empty.
+func (r *Ring) Pick(key string) int {
+	if len(r.points) == 0 {
+		return -1
+	}
+
+	h := crc32.ChecksumIEEE([]byte(key))
+
+	i, _ := slices.BinarySearchFunc(r.points, h, func(p point, h uint32) int { return cmp.Compare(p.hash, h) })
+
+	if i == len(r.points) {
+		i = 0 // wrap around
+	}
+
+	return r.points[i].node
+}
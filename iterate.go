@@ -0,0 +1,27 @@
+package cache
+
+// OrderedKeys returns the keys of all the valid items in the cache, from the most to the least
+// recently used, with the pinned items first (see Pin), followed by the protected segment under
+// SLRU policy. Under CLOCK policy, or with buffered promotion, the order is approximate.
+func (c *LRU[K, V]) OrderedKeys() []K {
+	recs := c.records()
+	keys := make([]K, len(recs))
+
+	for i, rec := range recs {
+		keys[i] = rec.Key
+	}
+
+	return keys
+}
+
+// RangeLRU calls the given function for each valid item in the cache, in the same order as
+// OrderedKeys, until the function returns false. The function is invoked on a copy of the items
+// made at the start of the call, so it can safely call any methods of the cache, and it does not
+// see the changes made after the start.
+func (c *LRU[K, V]) RangeLRU(fn func(K, V) bool) {
+	for _, rec := range c.records() {
+		if !fn(rec.Key, rec.Value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOrderedKeys(t *testing.T) {
+	c := New(5, time.Hour, simpleBackend)
+
+	if err := fill(c.Get, []int{1, 2, 3, 1000, 4, 2, 5, 6}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// errors are skipped
+	if keys := fmt.Sprint(c.OrderedKeys()); keys != "[6 5 2 4]" {
+		t.Errorf("unexpected keys: %s instead of [6 5 2 4]", keys)
+		return
+	}
+
+	var items []string
+
+	c.RangeLRU(func(key, value int) bool {
+		items = append(items, fmt.Sprintf("%d:%d", key, value))
+		c.Delete(key) // safe to call
+
+		return len(items) < 3
+	})
+
+	if s := fmt.Sprint(items); s != "[6:-6 5:-5 2:-2]" {
+		t.Errorf("unexpected items: %s", s)
+		return
+	}
+
+	if keys := fmt.Sprint(c.OrderedKeys()); keys != "[4]" {
+		t.Errorf("unexpected keys: %s instead of [4]", keys)
+		return
+	}
+}
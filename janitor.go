@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"time"
+	"unsafe"
+)
+
+// janitor goroutine
+func (c *LRU[K, V]) janitor(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.sweep()
+		}
+	}
+}
+
+// ExpireNow removes all the expired items from the cache, the same way the janitor does
+// (see WithJanitor), returning the number of the items removed. The expired items that can
+// still be served stale (see WithStaleWhileRevalidate) are kept.
+func (c *LRU[K, V]) ExpireNow() int {
+	return c.sweep()
+}
+
+// remove all expired nodes, returning the number of the nodes removed
+func (c *LRU[K, V]) sweep() (n int) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.applyHits()
+
+	now := c.now()
+
+	// pop the expired prefix of the heap (keeping the nodes that can still be served stale)
+	for len(c.expiry) > 0 && !now.Before(c.expiry[0].deadline().Add(c.maxStale)) {
+		c.drop(c.expiry[0], EvictExpired)
+		n++
+	}
+
+	if c.accessTTL > 0 {
+		// the least recently accessed nodes are at the bottom of the LRU lists
+		// (only approximately so under the CLOCK policy)
+		for _, root := range c.lists() {
+			n += c.sweepIdle(root, now)
+		}
+	}
+
+	return
+}
+
+// remove the idle nodes from the bottom of the given list
+func (c *LRU[K, V]) sweepIdle(root *listNode, now time.Time) (n int) {
+	for root.prev != root {
+		node := (*lruNode[K, V])(unsafe.Pointer(root.prev))
+
+		if now.Sub(node.atime) < c.accessTTL {
+			break
+		}
+
+		c.drop(node, EvictExpired)
+		n++
+	}
+
+	return
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJanitor(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, 10*time.Millisecond, backend.fn, WithJanitor(5*time.Millisecond))
+
+	defer c.Close()
+
+	if err := fill(c.Get, []int{1, 2, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	c.mu.Lock()
+	err := assertEmpty(c)
+	c.mu.Unlock()
+
+	if err != nil {
+		t.Error("expired items have not been removed:", err)
+		return
+	}
+
+	if err = matchTraces(backend.trace, []int{1, 2, 3}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestSweep(t *testing.T) {
+	c := NewWithTTL(10, time.Hour, func(key int) (int, time.Duration, error) {
+		v, err := simpleBackend(key)
+
+		if key%2 == 0 {
+			return v, time.Nanosecond, err
+		}
+
+		return v, 0, err
+	})
+
+	if err := fill(c.Get, []int{1, 2, 3, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if n := c.sweep(); n != 2 {
+		t.Errorf("unexpected number of expired nodes: %d instead of 2", n)
+		return
+	}
+
+	if err := checkState(c, []int{1, 3}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+}
+
+func TestSweepIdle(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend, WithExpireAfterAccess(20*time.Millisecond))
+
+	if err := fill(c.Get, []int{1, 2, 3, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := fill(c.Get, []int{2, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if n := c.sweep(); n != 2 {
+		t.Errorf("unexpected number of idle nodes: %d instead of 2", n)
+		return
+	}
+
+	if err := checkState(c, []int{2, 4}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	if len(c.expiry) != 2 {
+		t.Errorf("unexpected size of the expiry heap: %d instead of 2", len(c.expiry))
+		return
+	}
+}
+
+func TestExpireNow(t *testing.T) {
+	now := time.Now()
+
+	c := New(10, time.Minute, simpleBackend, WithClock(func() time.Time { return now }))
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	now = now.Add(30 * time.Second)
+
+	if err := fill(c.Get, []int{3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if n := c.ExpireNow(); n != 0 {
+		t.Error("unexpected number of expired items:", n)
+		return
+	}
+
+	now = now.Add(time.Minute)
+
+	if n := c.ExpireNow(); n != 3 || c.Len() != 0 {
+		t.Error("unexpected number of expired items:", n)
+		return
+	}
+}
@@ -0,0 +1,225 @@
+package cache
+
+import "time"
+
+const (
+	// fraction of the lifetime of a kept fresh item after which it gets reloaded, unless
+	// refresh-ahead is enabled
+	keepFreshAhead = 0.75
+
+	// delay before rechecking a kept fresh key that is being reloaded, and the initial delay
+	// before retrying a failed load, unless WithErrorBackoff option is set
+	keepFreshRetry = 10 * time.Millisecond
+
+	// max. delay before retrying a failed load, unless WithErrorBackoff option is set
+	keepFreshMaxRetry = 10 * time.Second
+)
+
+// registration of a kept fresh key
+type keptKey struct {
+	interval time.Duration // max. time between reloads (0 if none)
+	retry    time.Time     // earliest time of the next attempt to load or reload the key
+	failures int           // number of attempts since the key was last fresh in the cache
+	loading  bool          // true while the key is being loaded
+}
+
+// KeepFresh registers the given key to be reloaded by a background goroutine before the item
+// expires, indefinitely, so that the item is never served stale, and the callers never wait for
+// the backend. The item gets reloaded after the fraction of its lifetime set via WithRefreshAhead
+// option, or 3/4 of its lifetime otherwise, and it stays in the cache until replaced with the fresh
+// value. If the key is not in the cache, for example, after having been evicted or invalidated,
+// it gets loaded immediately. A failed load or reload is retried with exponential backoff, following
+// the schedule of WithErrorBackoff option, if set, or starting from 10ms, up to 10s. The registration lasts until StopKeepingFresh is called for the key,
+// or the cache is closed. See also Pin.
+func (c *LRU[K, V]) KeepFresh(key K) {
+	c.KeepFreshEvery([]K{key}, 0)
+}
+
+// KeepFreshEvery is the same as KeepFresh, but also reloads the given items at least every given
+// interval, if it is shorter than the time to reload before expiry. A zero or negative interval
+// means reloading before expiry only. Registering a key again replaces its interval.
+func (c *LRU[K, V]) KeepFreshEvery(keys []K, interval time.Duration) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return
+	}
+
+	if c.keep == nil {
+		c.keep = make(map[K]*keptKey, len(keys))
+		c.keepWake = make(chan struct{}, 1)
+
+		// the goroutine is started on demand, so it is not waited for on Close
+		go c.keeper(c.keepWake)
+	}
+
+	for _, key := range keys {
+		if k := c.keep[key]; k != nil {
+			k.interval = max(interval, 0)
+		} else {
+			c.keep[key] = &keptKey{interval: max(interval, 0)}
+		}
+	}
+
+	select {
+	case c.keepWake <- struct{}{}:
+	default:
+	}
+}
+
+// StopKeepingFresh cancels the registration of the given keys made via KeepFresh or
+// KeepFreshEvery. The items stay in the cache until evicted or expired, as usual.
+func (c *LRU[K, V]) StopKeepingFresh(keys ...K) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for _, key := range keys {
+		delete(c.keep, key)
+	}
+}
+
+// keeper goroutine
+func (c *LRU[K, V]) keeper(wake <-chan struct{}) {
+	timer := time.NewTimer(0)
+
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-wake:
+		case <-timer.C:
+		}
+
+		delay, ok := c.keepFresh()
+
+		if !ok {
+			return
+		}
+
+		timer.Reset(delay)
+	}
+}
+
+// reload or load the kept fresh items that are due, returning the delay until the next one
+// is due, or false if the cache has been closed
+func (c *LRU[K, V]) keepFresh() (delay time.Duration, ok bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return 0, false
+	}
+
+	now := c.now()
+	delay = NoExpiry
+
+	for key, k := range c.keep {
+		node := c.nodes[key]
+
+		switch {
+		case node == nil || !c.current(node) || node.ready && !c.fresh(node, now) && !c.stale(node, now):
+			// not in the cache, or no longer usable
+			if k.loading {
+				continue // the keeper is woken up when the load completes
+			}
+
+			if wait := k.retry.Sub(now); wait > 0 {
+				delay = min(delay, wait)
+				continue
+			}
+
+			k.loading = true
+			c.keepBackoff(k, now)
+
+			go c.keepLoad(key, k)
+		case !node.ready || node.refreshing:
+			delay = min(delay, keepFreshRetry)
+		default:
+			left := c.keepFreshTime(node, k.interval)
+
+			if left == NoExpiry {
+				k.failures = 0
+				continue
+			}
+
+			if left -= now.Sub(node.ts); left > 0 {
+				k.failures = 0
+				delay = min(delay, left)
+				continue
+			}
+
+			// the reload is due, but it has failed recently
+			if wait := k.retry.Sub(now); wait > 0 {
+				delay = min(delay, wait)
+				continue
+			}
+
+			if !c.degraded.Load() {
+				node.refreshing = true
+				c.refresh(node)
+			}
+
+			c.keepBackoff(k, now)
+			delay = min(delay, keepFreshRetry)
+		}
+	}
+
+	return delay, true
+}
+
+// load the kept fresh key in the background, waking up the keeper when done
+func (c *LRU[K, V]) keepLoad(key K, k *keptKey) {
+	c.Get(key)
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	k.loading = false
+
+	select {
+	case c.keepWake <- struct{}{}:
+	default:
+	}
+}
+
+// set the earliest time of the next attempt to load or reload the kept fresh key, in case
+// the current attempt fails (the cache must be locked)
+func (c *LRU[K, V]) keepBackoff(k *keptKey, now time.Time) {
+	delay, limit := keepFreshRetry, keepFreshMaxRetry
+
+	if c.errBackoff > 0 {
+		delay, limit = c.errBackoff, c.errBackoffMax
+	}
+
+	for n := k.failures; n > 0 && delay < limit; n-- {
+		delay *= 2
+	}
+
+	k.retry = now.Add(min(delay, limit))
+	k.failures++
+}
+
+// time after the node creation when the kept fresh node is due for reloading, or NoExpiry
+// if it is never due
+func (c *LRU[K, V]) keepFreshTime(node *lruNode[K, V], interval time.Duration) time.Duration {
+	left := node.lifetime()
+
+	if left != NoExpiry {
+		ahead := c.ahead
+
+		if ahead == 0 {
+			ahead = keepFreshAhead
+		}
+
+		left = time.Duration(float64(left) * ahead)
+	}
+
+	if interval > 0 {
+		left = min(left, interval)
+	}
+
+	return left
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeepFresh(t *testing.T) {
+	var calls atomic.Int32
+
+	backend := func(key int) (int, error) {
+		return int(calls.Add(1)), nil
+	}
+
+	c := New(10, 100*time.Millisecond, backend)
+
+	defer c.Close()
+
+	c.KeepFresh(1)
+
+	// wait for the initial load
+	for ts := time.Now(); calls.Load() == 0; time.Sleep(time.Millisecond) {
+		if time.Since(ts) > time.Second {
+			t.Error("the key has not been loaded")
+			return
+		}
+	}
+
+	// the item never expires, so every request is a hit
+	for ts := time.Now(); time.Since(ts) < 500*time.Millisecond; time.Sleep(5 * time.Millisecond) {
+		if _, err := c.Get(1); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	if s := c.Stats(); s.Misses != 1 || s.ExpiredHits != 0 {
+		t.Errorf("unexpected misses or expired hits: %d, %d", s.Misses, s.ExpiredHits)
+		return
+	}
+
+	if n := calls.Load(); n < 5 {
+		t.Error("too few backend calls:", n)
+		return
+	}
+
+	// no more reloads
+	c.StopKeepingFresh(1)
+	time.Sleep(50 * time.Millisecond)
+
+	n := calls.Load()
+
+	time.Sleep(200 * time.Millisecond)
+
+	if m := calls.Load(); m != n {
+		t.Errorf("unexpected backend calls after unregistration: %d instead of %d", m, n)
+		return
+	}
+}
+
+func TestKeepFreshEvery(t *testing.T) {
+	var calls atomic.Int32
+
+	backend := func(key int) (int, error) {
+		calls.Add(1)
+		return -key, nil
+	}
+
+	c := New(10, NoExpiry, backend)
+
+	c.KeepFreshEvery([]int{1, 2}, 20*time.Millisecond)
+	time.Sleep(110 * time.Millisecond)
+
+	if err := c.Close(); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// 2 initial loads plus about 5 reloads of each key
+	if n := calls.Load(); n < 6 || n > 14 {
+		t.Error("unexpected number of backend calls:", n)
+		return
+	}
+
+	// the goroutine is stopped on close
+	n := calls.Load()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if m := calls.Load(); m != n {
+		t.Errorf("unexpected backend calls after close: %d instead of %d", m, n)
+		return
+	}
+}
+
+func TestKeepFreshBackoff(t *testing.T) {
+	var calls, running atomic.Int32
+
+	backend := func(key int) (int, error) {
+		calls.Add(1)
+
+		if running.Add(1) > 1 {
+			t.Error("concurrent loads of the same key")
+		}
+
+		defer running.Add(-1)
+
+		time.Sleep(30 * time.Millisecond)
+		return 0, errors.New("backend failure")
+	}
+
+	c := New(10, time.Minute, backend, WithNoErrorCaching())
+
+	defer c.Close()
+
+	c.KeepFresh(1)
+	time.Sleep(time.Second)
+
+	// the retries after 10, 20, 40, 80, 160 and 320ms, plus the duration of the calls
+	if n := calls.Load(); n < 4 || n > 8 {
+		t.Error("unexpected number of backend calls:", n)
+		return
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// number of buckets in the latency histogram
+const numLatencyBuckets = 16
+
+// upper bound of the first latency bucket
+const minLatencyBound = 250 * time.Microsecond
+
+// LatencyHistogram is the distribution of the load durations (see Stats.LoadLatency), where
+// the bucket i counts the loads that took less than Bound(i), and at least Bound(i-1). The bounds
+// double from 250µs in the first bucket to about 4s in the last but one, while the last bucket
+// is unbounded.
+type LatencyHistogram [numLatencyBuckets]uint64
+
+// Bound returns the upper bound of the bucket i, or math.MaxInt64 for the last bucket.
+func (LatencyHistogram) Bound(i int) time.Duration {
+	if i >= numLatencyBuckets-1 {
+		return math.MaxInt64
+	}
+
+	return minLatencyBound << i
+}
+
+// Count returns the total number of loads in the histogram.
+func (h LatencyHistogram) Count() (n uint64) {
+	for _, v := range h {
+		n += v
+	}
+
+	return
+}
+
+// Quantile returns the upper bound of the bucket containing the given quantile (for example,
+// 0.99 for the 99th percentile) of the load durations, or 0 if the histogram is empty.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	total := h.Count()
+
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(total)))
+
+	var n uint64
+
+	for i, v := range h {
+		if n += v; n >= rank && n > 0 {
+			return h.Bound(i)
+		}
+	}
+
+	return h.Bound(numLatencyBuckets - 1)
+}
+
+// index of the bucket for the given latency
+func latencyBucket(d time.Duration) int {
+	if d < minLatencyBound {
+		return 0
+	}
+
+	return min(bits.Len64(uint64(d/minLatencyBound)), numLatencyBuckets-1)
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLatencyBuckets(t *testing.T) {
+	var h LatencyHistogram
+
+	cases := []struct {
+		d time.Duration
+		i int
+	}{
+		{0, 0},
+		{249 * time.Microsecond, 0},
+		{250 * time.Microsecond, 1},
+		{499 * time.Microsecond, 1},
+		{time.Millisecond, 3},
+		{4 * time.Second, 14},
+		{5 * time.Second, 15},
+		{time.Hour, 15},
+	}
+
+	for _, c := range cases {
+		i := latencyBucket(c.d)
+
+		if i != c.i {
+			t.Errorf("unexpected bucket for %s: %d instead of %d", c.d, i, c.i)
+			return
+		}
+
+		if c.d >= h.Bound(i) || (i > 0 && c.d < h.Bound(i-1)) {
+			t.Errorf("latency %s out of bucket bounds", c.d)
+			return
+		}
+	}
+
+	if b := h.Bound(numLatencyBuckets - 1); b != math.MaxInt64 {
+		t.Error("unexpected bound of the last bucket:", b)
+		return
+	}
+}
+
+func TestLoadLatency(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	// the key is the load latency in milliseconds
+	backend := func(key int) (int, error) {
+		now = now.Add(time.Duration(key) * time.Millisecond)
+		return -key, nil
+	}
+
+	c := New(10, time.Hour, backend, WithClock(clock))
+
+	if q := c.Stats().LoadLatency.Quantile(0.5); q != 0 {
+		t.Error("unexpected quantile of empty histogram:", q)
+		return
+	}
+
+	if err := fill(c.Get, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 3, 5, 7, 11, 13, 17, 19, 23, 50}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	s := c.Stats()
+
+	if n := s.LoadLatency.Count(); n != s.Loads || n != 11 {
+		t.Error("unexpected number of loads in histogram:", n)
+		return
+	}
+
+	if q := s.LoadLatency.Quantile(0.5); q != 16*time.Millisecond {
+		t.Error("unexpected median:", q)
+		return
+	}
+
+	if q := s.LoadLatency.Quantile(1); q != 64*time.Millisecond {
+		t.Error("unexpected max.:", q)
+		return
+	}
+
+	if info, _ := c.Info(50); info.LoadDuration != 50*time.Millisecond {
+		t.Error("unexpected load duration:", info.LoadDuration)
+		return
+	}
+
+	if d := c.StatsDelta().Sub(s); d.LoadLatency.Count() != 0 {
+		t.Error("unexpected histogram difference:", d.LoadLatency)
+		return
+	}
+}
+
+func TestLatencyCost(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	backend := func(key int) (int, error) {
+		now = now.Add(time.Duration(key) * time.Millisecond)
+		return -key, nil
+	}
+
+	c := New(3, time.Hour, backend,
+		WithPolicy(PolicyGreedyDual),
+		WithLatencyCost(),
+		WithClock(clock))
+
+	// the fastest item to load gets evicted
+	if err := fill(c.Get, []int{20, 1, 10, 30}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{20, 10, 30}, validKey); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := TryNew(3, time.Hour, backend, WithLatencyCost(),
+		WithCost(func(int, int) float64 { return 1 })); err == nil {
+		t.Error("missing error for conflicting options")
+		return
+	}
+}
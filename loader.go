@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRateLimited is returned from the cache instead of invoking the backend when the rate
+// of backend calls exceeds the limit set via WithLoadRateLimit option.
+var ErrRateLimited = errors.New("backend rate limit exceeded")
+
+// ErrOverloaded is returned from the cache instead of invoking the backend when the number
+// of loads in progress reaches the limit set via WithLoadShedding option.
+var ErrOverloaded = errors.New("too many loads in progress")
+
+// RateLimiter is the interface to a rate limiter for backend calls. The interface is
+// satisfied by *rate.Limiter from golang.org/x/time/rate package.
+type RateLimiter interface {
+	Allow() bool
+	Wait(ctx context.Context) error
+}
+
+// WithFallback adds a loader to try when the backend function, or the previous fallback loader,
+// returns an error other than ErrNotFound, for example, to read a stale replica, or to provide
+// a static default, before the error gets cached. The fallback loaders are tried in the order
+// of the options, once each, without retries, rate limiting, or loader middleware, and their
+// calls are counted separately in the statistics (see Stats.Fallbacks). The key and value types
+// of the function must match those of the cache.
+func WithFallback[K comparable, V any](loader Loader[K, V]) Option {
+	return func(cfg *config) {
+		cfg.fallbacks = append(cfg.fallbacks, loader)
+	}
+}
+
+// invoke the backend function for the given key, with retries and fallbacks, if any
+func (c *LRU[K, V]) fetch(key K, backend func(K) (V, time.Duration, error)) (value V, ttl time.Duration, err error) {
+	value, ttl, err = c.retry(key, backend)
+
+	for _, loader := range c.fallbacks {
+		if err == nil || errors.Is(err, ErrNotFound) {
+			break
+		}
+
+		c.stats.fallbacks.Add(1)
+
+		if value, ttl, err = loader(key); err != nil {
+			c.stats.fallbackErrors.Add(1)
+		}
+	}
+
+	return
+}
+
+// invoke the backend function for the given key, retrying on error if configured to do so
+func (c *LRU[K, V]) retry(key K, backend func(K) (V, time.Duration, error)) (value V, ttl time.Duration, err error) {
+	if c.degraded.Load() {
+		err = ErrUnhealthy
+		return
+	}
+
+	if c.shedding > 0 {
+		if c.inflight.Add(1) > int64(c.shedding) {
+			c.inflight.Add(-1)
+			c.stats.shed.Add(1)
+			err = ErrOverloaded
+			return
+		}
+
+		defer c.inflight.Add(-1)
+	}
+
+	delay := c.backoff
+
+	for i := 1; ; i++ {
+		if err = c.throttle(); err != nil {
+			return
+		}
+
+		c.stats.loads.Add(1)
+
+		if value, ttl, err = c.call(backend, key); err == nil {
+			return
+		}
+
+		if errors.Is(err, ErrNotFound) {
+			c.stats.notFound.Add(1)
+			return
+		}
+
+		if c.stats.loadErrors.Add(1); i >= c.attempts {
+			return
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// invoke the backend function within the limit on concurrent loads, if any
+func (c *LRU[K, V]) call(backend func(K) (V, time.Duration, error), key K) (V, time.Duration, error) {
+	if c.loading != nil {
+		c.loading <- struct{}{}
+
+		defer func() { <-c.loading }()
+	}
+
+	if c.jobs != nil {
+		return c.dispatch(backend, key)
+	}
+
+	return backend(key)
+}
+
+// apply the rate limit, if any
+func (c *LRU[K, V]) throttle() error {
+	switch {
+	case c.limiter == nil:
+		return nil
+	case c.waitLimiter:
+		return c.limiter.Wait(context.Background())
+	case c.limiter.Allow():
+		return nil
+	default:
+		return ErrRateLimited
+	}
+}
+
+// check if the error is returned instead of invoking the backend
+func refused(err error) bool {
+	return err == ErrRateLimited || err == ErrUnhealthy || err == ErrOverloaded || err == ErrQueueTimeout
+}
+
+// check if the error is a negative result to be cached with its own TTL
+func (c *LRU[K, V]) negative(err error) bool {
+	return c.notFoundTTL > 0 && errors.Is(err, ErrNotFound)
+}
+
+// time-to-live of the loaded node with the error backoff applied (the cache must be locked)
+func (c *LRU[K, V]) errorBackoff(node *lruNode[K, V], ttl time.Duration) time.Duration {
+	switch {
+	case node.err == nil:
+		delete(c.failures, node.key)
+		return ttl
+	case refused(node.err) || c.errorPolicy != nil || c.negative(node.err):
+		return ttl
+	}
+
+	n, found := c.failures[node.key]
+
+	if !found && len(c.failures) >= c.size {
+		// keep the state bounded by forgetting an arbitrary key
+		for k := range c.failures {
+			delete(c.failures, k)
+			break
+		}
+	}
+
+	c.failures[node.key] = n + 1
+
+	for ttl = c.errBackoff; n > 0 && ttl < c.errBackoffMax; n-- {
+		ttl *= 2
+	}
+
+	return min(ttl, c.errBackoffMax)
+}
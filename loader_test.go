@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadRetry(t *testing.T) {
+	var calls []int
+
+	errTransient := errors.New("transient error")
+
+	backend := func(key int) (int, error) {
+		if calls = append(calls, key); len(calls) < 3 {
+			return 0, errTransient
+		}
+
+		return simpleBackend(key)
+	}
+
+	c := New(10, time.Hour, backend, WithLoadRetry(3, time.Millisecond))
+
+	if err := getOne(c, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := matchTraces(calls, []int{1, 1, 1}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	// permanent error
+	calls = nil
+
+	if _, err := c.Get(1000); err == nil {
+		t.Error("missing error")
+		return
+	}
+
+	if err := matchTraces(calls, []int{1000, 1000, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestLoadRateLimit(t *testing.T) {
+	var backend tracingBackend
+
+	limiter := &countingLimiter{n: 2}
+
+	c := New(10, time.Hour, backend.fn, WithLoadRateLimit(limiter, false))
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if _, err := c.Get(3); err != ErrRateLimited {
+		t.Errorf("unexpected error: %v instead of %v", err, ErrRateLimited)
+		return
+	}
+
+	// rate limiting errors are not cached
+	limiter.n = 1
+
+	if err := fill(c.Get, []int{1, 2, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 3}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+// rate limiter that allows a given number of calls
+type countingLimiter struct {
+	n int
+}
+
+func (l *countingLimiter) Allow() bool {
+	if l.n > 0 {
+		l.n--
+		return true
+	}
+
+	return false
+}
+
+func (l *countingLimiter) Wait(context.Context) error {
+	if !l.Allow() {
+		return ErrRateLimited
+	}
+
+	return nil
+}
+
+func TestMaxConcurrentLoads(t *testing.T) {
+	var running, peak atomic.Int32
+
+	c := New(100, time.Hour, func(key int) (int, error) {
+		n := running.Add(1)
+
+		defer running.Add(-1)
+
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+
+		time.Sleep(2 * time.Millisecond)
+		return simpleBackend(key)
+	}, WithMaxConcurrentLoads(2))
+
+	keys := make([]int, 20)
+
+	for i := range keys {
+		keys[i] = i
+	}
+
+	if err := getConcurrently(c, keys); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := peak.Load(); n != 2 {
+		t.Errorf("unexpected max. number of concurrent loads: %d instead of 2", n)
+		return
+	}
+}
+
+func TestErrorBackoff(t *testing.T) {
+	fail := true
+
+	c := New(10, time.Hour, func(key int) (int, error) {
+		if fail {
+			return 0, errors.New("failure")
+		}
+
+		return simpleBackend(key)
+	}, WithErrorBackoff(time.Second, 5*time.Second))
+
+	// each consecutive failure doubles the error TTL, up to the limit
+	for _, exp := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if _, err := c.Get(1); err == nil {
+			t.Error("missing error")
+			return
+		}
+
+		node := c.nodes[1]
+
+		if d := node.lifetime(); d != exp {
+			t.Errorf("unexpected error TTL: %s instead of %s", d, exp)
+			return
+		}
+
+		node.ts = node.ts.Add(-exp) // expire the error
+	}
+
+	// success resets the backoff
+	fail = false
+
+	if err := getOne(c, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(c.failures) != 0 {
+		t.Error("the backoff state has not been reset")
+		return
+	}
+}
+
+func TestFallback(t *testing.T) {
+	var log []string
+
+	loader := func(name string, fail bool) Loader[int, int] {
+		return func(key int) (int, time.Duration, error) {
+			log = append(log, fmt.Sprintf("%s:%d", name, key))
+
+			switch {
+			case key == 0:
+				return 0, 0, ErrNotFound
+			case fail || key > 1:
+				return 0, 0, errors.New(name + " failed")
+			default:
+				return -key, 0, nil
+			}
+		}
+	}
+
+	c := NewWithTTL(10, time.Hour, loader("primary", true),
+		WithLoadRetry(2, time.Microsecond),
+		WithFallback(loader("replica", true)),
+		WithFallback(loader("default", false)))
+
+	// the last fallback provides the value
+	if v, err := c.Get(1); err != nil || v != -1 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	// all the loaders fail, so the error of the last one is cached
+	if _, err := c.Get(2); err == nil || err.Error() != "default failed" {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// no fallback for the missing key
+	if _, err := c.Get(0); err != ErrNotFound {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	exp := []string{
+		"primary:1", "primary:1", "replica:1", "default:1",
+		"primary:2", "primary:2", "replica:2", "default:2",
+		"primary:0",
+	}
+
+	if err := matchLogs(log, exp); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if s := c.Stats(); s.Loads != 5 || s.LoadErrors != 4 || s.Fallbacks != 4 || s.FallbackErrors != 3 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+}
+
+func TestLoadShedding(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+
+	backend := func(key int) (int, error) {
+		if key == 1 {
+			close(started)
+			<-release
+		}
+
+		return -key, nil
+	}
+
+	c := New(10, time.Hour, backend, WithLoadShedding(1))
+	done := make(chan error)
+
+	go func() {
+		_, err := c.Get(1)
+		done <- err
+	}()
+
+	<-started
+
+	// the limit is reached
+	if _, err := c.Get(2); err != ErrOverloaded {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// the error is not cached
+	if v, err := c.Get(2); err != nil || v != -2 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	if s := c.Stats(); s.Shed != 1 || s.Loads != 2 || s.LoadErrors != 0 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+}
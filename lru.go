@@ -1,24 +1,113 @@
 package cache
 
 import (
+	"container/heap"
+	"context"
 	"errors"
+	"hash/maphash"
+	"math"
+	"math/rand"
+	"runtime/debug"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 )
 
 const maxCacheSize = 64 * 1024 * 1024 // arbitrary large number
 
+// NoExpiry is the time-to-live of the items that never expire by time. Zero TTL given to
+// a constructor has the same meaning.
+const NoExpiry time.Duration = math.MaxInt64
+
 // LRU is an opaque type representing an LRU cache with keys of type "K" and values of type "V".
 type LRU[K comparable, V any] struct {
-	mu    sync.Mutex           // mutex to protect the cache
-	nodes map[K]*lruNode[K, V] // mapping from keys to nodes
-	list  listNode             // LRU list
+	mu     sync.Mutex           // mutex to protect the cache
+	nodes  map[K]*lruNode[K, V] // mapping from keys to nodes
+	list   listNode             // LRU list (probation segment under SLRU policy)
+	expiry expiryHeap[K, V]     // nodes ordered by expiration time
+	index  sync.Map             // lock-free copy of the key mapping (with lock-free hits only)
+
+	protected listNode // protected segment of the SLRU list
+	numProt   int      // number of nodes in the protected segment
+	maxProt   int      // max. number of nodes in the protected segment
+
+	pinned listNode   // list of pinned nodes, exempt from eviction
+	pins   map[K]bool // pinned keys
+
+	high listNode // list of high priority nodes
+	low  listNode // list of low priority nodes
+
+	keep     map[K]*keptKey // keys kept fresh (see KeepFresh)
+	keepWake chan struct{}  // wakes up the keeper goroutine
+
+	watchers map[K]map[chan V]struct{} // subscribers to the changes of each key (see Watch)
+
+	gen    atomic.Uint64             // current generation of the items (see InvalidateAll)
+	coarse atomic.Pointer[time.Time] // current time of the coarse clock (nil if disabled)
+
+	degraded atomic.Bool  // true while the backend is unhealthy (see WithHealthCheck)
+	inflight atomic.Int64 // number of loads in progress (with load shedding only)
+
+	size    int                               // max. number of items in the cache
+	ttl     time.Duration                     // time-to-live for each item
+	backend func(K) (V, time.Duration, error) // function for fetching data on cache miss
+	source  func(K) (V, time.Duration, error) // backend function before the middleware
+
+	config // optional parameters
+
+	lockFree bool             // true if cache hits do not need locking
+	timeless bool             // true if the items never expire by time by default
+	hits     hitBuffers[K, V] // buffered promotions
+	pooled   bool             // true if the nodes are reused
+	pool     sync.Pool        // pool of free nodes
+
+	weigher   func(K, V) int // function to calculate weight of each item
+	maxWeight int            // max. total weight of the items in the cache
+	weight    int            // current total weight of the items in the cache
+
+	coster    func(K, V) float64 // function to calculate cost of each item (GreedyDual only)
+	inflation float64            // priority of the last evicted item (GreedyDual only)
+
+	samples  hitSamples              // hit ratio samples (with hit ratio window only)
+	hot      *hotKeys[K]             // most frequent keys (nil if not tracked)
+	door     *doorkeeper             // admission filter (nil if disabled)
+	freq     *freqSketch[K]          // request frequencies (nil if not tracked)
+	onEvict  func(K, V, EvictReason) // eviction callback
+	evicted  []eviction[K, V]        // evictions to report after unlocking the cache
+	stats    counters                // statistics
+	last     lastStats               // statistics at the previous call to StatsDelta
+	events   chan Event[K]           // lifecycle events (nil if disabled)
+	loading  chan struct{}           // semaphore for backend calls (nil if unlimited)
+	jobs     chan *job               // worker pool queue (nil if disabled)
+	failures map[K]int               // number of consecutive load failures (with error backoff only)
+	publish  func(K)                 // invalidation publisher
+	onAdd    func(K)                 // internal hook invoked when a key enters the cache
+	onDrop   func(K)                 // internal hook invoked when a key leaves the cache
+
+	tagger func(K, V) []string                    // function to calculate tags of each item
+	tags   map[string]map[*lruNode[K, V]]struct{} // items per tag
+	writer func(context.Context, K, V) error      // function for writing data to the backend
+
+	prioritiser func(K, V) Priority // function to calculate priority of each item
 
-	size    int                // max. number of items in the cache
-	ttl     time.Duration      // time-to-live for each item
-	backend func(K) (V, error) // function for fetching data on cache miss
+	keyString func(K) string // key to string conversion (nil if unavailable)
+	prefixes  *prefixTrie[K] // prefix tree of the keys (nil if disabled)
+
+	ordered keyIndex[K] // ordered index of the keys (nil if disabled)
+
+	dirty    map[K]V    // queued writes (in write-back mode only)
+	flushing map[K]V    // writes being flushed
+	flushMu  sync.Mutex // serialises flushes
+
+	middleware []func(Loader[K, V]) Loader[K, V] // loader decorators
+	fallbacks  []Loader[K, V]                    // fallback loaders
+
+	done      chan struct{}  // channel to stop background goroutines
+	wg        sync.WaitGroup // background goroutines
+	closeOnce sync.Once      // for closing the cache only once
+	closed    bool           // true after the cache has been closed
 }
 
 // New creates a new LRU cache with keys of type "K" and values of type "V".
@@ -26,23 +115,336 @@ func New[K comparable, V any](
 	size int,
 	ttl time.Duration,
 	backend func(K) (V, error),
-) (c *LRU[K, V]) {
+	opts ...Option,
+) *LRU[K, V] {
+	if backend == nil {
+		panic("attempt to create an LRU cache with nil backend function")
+	}
+
+	return newLRU(size, ttl, func(key K) (value V, _ time.Duration, err error) {
+		value, err = backend(key)
+		return
+	}, opts)
+}
+
+// TryNew is the same as New, but returns an error instead of panicking when a parameter or
+// an option is invalid. The returned error is always of type *ConfigError.
+func TryNew[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	backend func(K) (V, error),
+	opts ...Option,
+) (*LRU[K, V], error) {
+	if backend == nil {
+		return nil, configError(ErrNilBackend, "nil backend function")
+	}
+
+	return tryNewLRU(size, ttl, func(key K) (value V, _ time.Duration, err error) {
+		value, err = backend(key)
+		return
+	}, opts)
+}
+
+// NewWithTTL creates a new LRU cache with keys of type "K" and values of type "V", where
+// the backend function also returns time-to-live for each item. A non-positive TTL
+// returned from the backend is replaced with the default TTL of the cache.
+func NewWithTTL[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	backend func(K) (V, time.Duration, error),
+	opts ...Option,
+) *LRU[K, V] {
+	if backend == nil {
+		panic("attempt to create an LRU cache with nil backend function")
+	}
+
+	return newLRU(size, ttl, backend, opts)
+}
+
+// common constructor
+func newLRU[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	backend func(K) (V, time.Duration, error),
+	opts []Option,
+) *LRU[K, V] {
+	c, err := tryNewLRU(size, ttl, backend, opts)
+
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return c
+}
+
+// common constructor returning validation errors
+func tryNewLRU[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	backend func(K) (V, time.Duration, error),
+	opts []Option,
+) (c *LRU[K, V], err error) {
 	// parameter validation
-	if size < 2 || size > maxCacheSize {
-		panic("attempt to create an LRU cache with invalid capacity of " +
-			strconv.Itoa(size) + " items")
+	if size < 1 || size > maxCacheSize {
+		return nil, configError(ErrInvalidCapacity, "invalid capacity of "+
+			strconv.Itoa(size)+" items")
 	}
 
 	switch {
 	case ttl < 0:
-		panic("attempt to create an LRU cache with negative TTL")
+		return nil, configError(ErrInvalidTTL, "negative TTL")
 	case ttl == 0:
-		// keep "forever"
-		ttl = 50 * 365 * 24 * time.Hour
+		ttl = NoExpiry
 	}
 
-	if backend == nil {
-		panic("attempt to create an LRU cache with nil backend function")
+	cfg := makeConfig(opts)
+
+	if cfg.policy < PolicyLRU || cfg.policy > PolicyGreedyDual {
+		return nil, configError(ErrInvalidOption, "invalid eviction policy "+
+			strconv.Itoa(int(cfg.policy)))
+	}
+
+	if cfg.accessTTL < 0 {
+		return nil, configError(ErrInvalidOption, "negative expire-after-access timeout")
+	}
+
+	if cfg.errorTTL < 0 {
+		return nil, configError(ErrInvalidOption, "negative error TTL")
+	}
+
+	if cfg.notFoundTTL < 0 {
+		return nil, configError(ErrInvalidOption, "negative not-found TTL")
+	}
+
+	if cfg.jitter < 0 || cfg.jitter >= 1 {
+		return nil, configError(ErrInvalidOption, "invalid TTL jitter of "+
+			strconv.FormatFloat(cfg.jitter, 'g', -1, 64))
+	}
+
+	if cfg.maxStale < 0 {
+		return nil, configError(ErrInvalidOption, "negative stale-while-revalidate timeout")
+	}
+
+	if cfg.ahead < 0 || cfg.ahead >= 1 {
+		return nil, configError(ErrInvalidOption, "invalid refresh-ahead fraction of "+
+			strconv.FormatFloat(cfg.ahead, 'g', -1, 64))
+	}
+
+	if cfg.attempts < 0 || cfg.backoff < 0 {
+		return nil, configError(ErrInvalidOption, "invalid load retry parameters")
+	}
+
+	if cfg.janitor < 0 {
+		return nil, configError(ErrInvalidOption, "negative janitor interval")
+	}
+
+	if cfg.errBackoff < 0 || (cfg.errBackoff > 0 && cfg.errBackoffMax < cfg.errBackoff) {
+		return nil, configError(ErrInvalidOption, "invalid error backoff parameters")
+	}
+
+	if a := cfg.adaptive; a.interval != 0 {
+		if a.interval < 0 || a.target <= 0 || a.target >= 1 || a.min < 1 || a.max > maxCacheSize ||
+			size < a.min || size > a.max || cfg.unbounded {
+			return nil, configError(ErrInvalidOption, "invalid adaptive capacity parameters")
+		}
+	}
+
+	if cfg.maxTTL != 0 {
+		if cfg.minTTL <= 0 || ttl < cfg.minTTL || ttl > cfg.maxTTL {
+			return nil, configError(ErrInvalidOption, "invalid adaptive TTL bounds")
+		}
+
+		if cfg.ahead == 0 && cfg.maxStale == 0 {
+			return nil, configError(ErrInvalidOption, "adaptive TTL without background reloads")
+		}
+	}
+
+	if cfg.hotKeys < 0 {
+		return nil, configError(ErrInvalidOption, "negative number of hot keys")
+	}
+
+	if cfg.sketch < 0 {
+		return nil, configError(ErrInvalidOption, "negative frequency sketch size")
+	}
+
+	if cfg.door < 0 {
+		return nil, configError(ErrInvalidOption, "negative doorkeeper window")
+	}
+
+	if cfg.window < 0 {
+		return nil, configError(ErrInvalidOption, "negative hit ratio window")
+	}
+
+	switch {
+	case cfg.tick < 0:
+		return nil, configError(ErrInvalidOption, "negative coarse clock resolution")
+	case cfg.tick > 0 && cfg.clock != nil:
+		return nil, configError(ErrInvalidOption, "both clock function and coarse clock")
+	}
+
+	if w := cfg.workers; w.workers < 0 || w.queue < 0 || w.timeout < 0 {
+		return nil, configError(ErrInvalidOption, "invalid worker pool parameters")
+	}
+
+	if cfg.health != nil && cfg.probe <= 0 {
+		return nil, configError(ErrInvalidOption, "invalid health check interval")
+	}
+
+	if cfg.maxLoads < 0 || cfg.shedding < 0 {
+		return nil, configError(ErrInvalidOption, "negative limit on concurrent loads")
+	}
+
+	if cfg.events < 0 {
+		return nil, configError(ErrInvalidOption, "negative event channel capacity")
+	}
+
+	var weigher func(K, V) int
+
+	switch {
+	case cfg.maxBytes != 0 && cfg.weigher != nil:
+		return nil, configError(ErrInvalidOption, "both weigher function and max. bytes limit")
+
+	case cfg.maxBytes != 0:
+		if cfg.maxBytes < 1 {
+			return nil, configError(ErrInvalidOption, "invalid max. bytes limit of "+
+				strconv.Itoa(cfg.maxBytes))
+		}
+
+		weigher, cfg.maxWeight = byteWeigher[K, V](), cfg.maxBytes
+
+	case cfg.weigher != nil:
+		var ok bool
+
+		if weigher, ok = cfg.weigher.(func(K, V) int); !ok {
+			return nil, configError(ErrInvalidOption, "weigher function of invalid type")
+		}
+
+		if cfg.maxWeight < 1 {
+			return nil, configError(ErrInvalidOption, "invalid max. weight of "+
+				strconv.Itoa(cfg.maxWeight))
+		}
+	}
+
+	var coster func(K, V) float64
+
+	if cfg.cost != nil {
+		var ok bool
+
+		if cfg.latencyCost {
+			return nil, configError(ErrInvalidOption, "both cost function and latency cost")
+		}
+
+		if coster, ok = cfg.cost.(func(K, V) float64); !ok {
+			return nil, configError(ErrInvalidOption, "cost function of invalid type")
+		}
+	}
+
+	var onEvict func(K, V, EvictReason)
+
+	if cfg.onEvict != nil {
+		var ok bool
+
+		if onEvict, ok = cfg.onEvict.(func(K, V, EvictReason)); !ok {
+			return nil, configError(ErrInvalidOption, "eviction callback of invalid type")
+		}
+	}
+
+	var prioritiser func(K, V) Priority
+
+	if cfg.priority != nil {
+		var ok bool
+
+		if prioritiser, ok = cfg.priority.(func(K, V) Priority); !ok {
+			return nil, configError(ErrInvalidOption, "priority function of invalid type")
+		}
+	}
+
+	// string keys need no conversion
+	keyString, _ := any(func(s string) string { return s }).(func(K) string)
+
+	if cfg.keyString != nil {
+		var ok bool
+
+		if keyString, ok = cfg.keyString.(func(K) string); !ok {
+			return nil, configError(ErrInvalidOption, "key string function of invalid type")
+		}
+	}
+
+	if cfg.prefixIndex && keyString == nil {
+		return nil, configError(ErrInvalidOption, "prefix index requires string keys or key string function")
+	}
+
+	var ordered keyIndex[K]
+
+	if cfg.orderedIndex != nil {
+		newIndex, ok := cfg.orderedIndex.(func() keyIndex[K])
+
+		if !ok {
+			return nil, configError(ErrInvalidOption, "ordered index of invalid type")
+		}
+
+		ordered = newIndex()
+	}
+
+	var publish func(K)
+
+	if cfg.publish != nil {
+		var ok bool
+
+		if publish, ok = cfg.publish.(func(K)); !ok {
+			return nil, configError(ErrInvalidOption, "invalidation publisher of invalid type")
+		}
+	}
+
+	var tagger func(K, V) []string
+
+	if cfg.tagger != nil {
+		var ok bool
+
+		if tagger, ok = cfg.tagger.(func(K, V) []string); !ok {
+			return nil, configError(ErrInvalidOption, "tagger function of invalid type")
+		}
+	}
+
+	var writer func(context.Context, K, V) error
+
+	if cfg.writer != nil {
+		var ok bool
+
+		if writer, ok = cfg.writer.(func(context.Context, K, V) error); !ok {
+			return nil, configError(ErrInvalidOption, "writer function of invalid type")
+		}
+	}
+
+	switch {
+	case cfg.writeBack < 0:
+		return nil, configError(ErrInvalidOption, "negative write-back interval")
+	case cfg.writeBack > 0 && writer == nil:
+		return nil, configError(ErrInvalidOption, "write-back without writer function")
+	}
+
+	var middleware []func(Loader[K, V]) Loader[K, V]
+
+	for _, mw := range cfg.middleware {
+		fn, ok := mw.(func(Loader[K, V]) Loader[K, V])
+
+		if !ok || fn == nil {
+			return nil, configError(ErrInvalidOption, "loader middleware of invalid type")
+		}
+
+		middleware = append(middleware, fn)
+	}
+
+	var fallbacks []Loader[K, V]
+
+	for _, fb := range cfg.fallbacks {
+		fn, ok := fb.(Loader[K, V])
+
+		if !ok || fn == nil {
+			return nil, configError(ErrInvalidOption, "fallback loader of invalid type")
+		}
+
+		fallbacks = append(fallbacks, fn)
 	}
 
 	// new cache
@@ -51,87 +453,906 @@ func New[K comparable, V any](
 		size:    size,
 		ttl:     ttl,
 		backend: backend,
+		source:  backend,
+		config:  cfg,
+		done:    make(chan struct{}),
+
+		weigher:   weigher,
+		maxWeight: cfg.maxWeight,
+		coster:    coster,
+		onEvict:   onEvict,
+		publish:   publish,
+		tagger:    tagger,
+		writer:    writer,
+
+		prioritiser: prioritiser,
+		keyString:   keyString,
+
+		ordered: ordered,
+
+		middleware: middleware,
+		fallbacks:  fallbacks,
+	}
+
+	c.backend = c.decorate(backend)
+
+	if cfg.events > 0 {
+		c.events = make(chan Event[K], cfg.events)
+	}
+
+	if cfg.maxLoads > 0 {
+		c.loading = make(chan struct{}, cfg.maxLoads)
+	}
+
+	if cfg.errBackoff > 0 {
+		c.failures = make(map[K]int)
+	}
+
+	if cfg.hotKeys > 0 {
+		c.hot = newHotKeys[K](cfg.hotKeys)
+	}
+
+	if cfg.sketch > 0 {
+		c.freq = newFreqSketch[K](cfg.sketch)
 	}
 
-	// prime the LRU list
+	if cfg.door > 0 {
+		c.door = newDoorkeeper(cfg.door)
+	}
+
+	if cfg.prefixIndex {
+		c.prefixes = &prefixTrie[K]{}
+	}
+
+	if cfg.writeBack > 0 {
+		c.dirty = make(map[K]V)
+	}
+
+	// under the CLOCK policy, or with buffered promotion, a cache hit does not modify
+	// the cache immediately, unless any per-access bookkeeping is required
+	c.lockFree = (cfg.policy == PolicyClock || cfg.buffered) && cfg.accessTTL == 0 && cfg.ahead == 0
+
+	// with no time-based expiration, cache hits need not read the clock
+	c.timeless = ttl == NoExpiry && cfg.accessTTL == 0 && cfg.ahead == 0
+
+	// lock-free cache hits do not track node references, so the nodes cannot be reused
+	if c.pooled = cfg.pooled && !c.lockFree; c.pooled {
+		c.pool.New = func() any { return new(lruNode[K, V]) }
+	}
+
+	// prime the LRU lists
 	c.list.next, c.list.prev = &c.list, &c.list
+	c.protected.next, c.protected.prev = &c.protected, &c.protected
+	c.pinned.next, c.pinned.prev = &c.pinned, &c.pinned
+	c.high.next, c.high.prev = &c.high, &c.high
+	c.low.next, c.low.prev = &c.low, &c.low
+
+	if cfg.unbounded {
+		c.size, c.maxProt = math.MaxInt, math.MaxInt
+	} else {
+		c.resize(size)
+	}
+
+	// background goroutines
+	if cfg.tick > 0 {
+		now := time.Now()
+
+		c.coarse.Store(&now)
+		c.wg.Add(1)
+		go c.ticker(cfg.tick)
+	}
+
+	if cfg.janitor > 0 {
+		c.wg.Add(1)
+		go c.janitor(cfg.janitor)
+	}
+
+	if cfg.writeBack > 0 {
+		c.wg.Add(1)
+		go c.flusher(cfg.writeBack)
+	}
+
+	if cfg.adaptive.interval > 0 {
+		c.wg.Add(1)
+		go c.tuner(cfg.adaptive.interval)
+	}
+
+	if cfg.window > 0 {
+		c.wg.Add(1)
+		go c.sampler(cfg.window / numWindowSamples)
+	}
+
+	if cfg.health != nil {
+		c.wg.Add(1)
+		go c.prober(cfg.health, cfg.probe)
+	}
+
+	if cfg.workers.workers > 0 {
+		c.jobs = make(chan *job, cfg.workers.queue)
+		c.wg.Add(cfg.workers.workers)
+
+		for range cfg.workers.workers {
+			go c.worker()
+		}
+	}
 
 	return
 }
 
-// Delete evicts the given key from the cache.
+// Delete evicts the given key from the cache, and publishes the invalidation if the cache
+// has been created with WithInvalidationPublisher option. A load of the key in progress is
+// not interrupted, and its result is still returned to the callers waiting for it, but it
+// never gets cached.
 func (c *LRU[K, V]) Delete(key K) {
+	c.remove(key)
+
+	if c.publish != nil {
+		c.publish(key)
+	}
+}
+
+// DeleteAndWait is the same as Delete, but also waits for the load of the key in progress,
+// if any, to complete, so that after the call the backend is not being invoked on behalf
+// of the deleted item. Background reloads (see WithRefreshAhead) are not waited for, but their
+// results get discarded as well.
+func (c *LRU[K, V]) DeleteAndWait(key K) {
+	c.mu.Lock()
+
+	node := c.nodes[key]
+
+	if node != nil {
+		c.acquire(node)
+		c.drop(node, EvictDeleted)
+	}
+
+	c.unlock()
+
+	if c.publish != nil {
+		c.publish(key)
+	}
+
+	if node != nil {
+		defer c.release(node)
+
+		// the load may have not started yet, in which case it is done here
+		node.once.Do(func() { c.load(node, c.backend) })
+	}
+}
+
+// InvalidateAll makes all the items currently in the cache invalid, in constant time, apart from
+// notifying the watchers (see Watch). The invalid items are never served, and get removed lazily,
+// either on access, or when evicted for capacity, counting towards the size of the cache until
+// then. The loads in progress are not affected, but their results are also invalid.
+func (c *LRU[K, V]) InvalidateAll() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+
+	c.deliverInvalidation()
+	c.gen.Add(1)
+}
+
+// ApplyInvalidation evicts the given key from the cache in response to the invalidation
+// published by another replica of the cache. Unlike Delete, it does not publish the
+// invalidation again.
+func (c *LRU[K, V]) ApplyInvalidation(key K) {
+	c.remove(key)
+}
+
+// delete the key from the cache
+func (c *LRU[K, V]) remove(key K) {
+	c.mu.Lock()
+	defer c.unlock()
 
 	if node := c.nodes[key]; node != nil {
-		delete(c.nodes, key)
-		node.purge()
+		c.drop(node, EvictDeleted)
+	}
+}
+
+// delete all the keys matching the given predicate, returning the number of keys deleted
+func (c *LRU[K, V]) removeIf(match func(K) bool) (n int) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for key, node := range c.nodes {
+		if match(key) {
+			c.drop(node, EvictDeleted)
+			n++
+		}
+	}
+
+	return
+}
+
+// Close stops all background goroutines of the cache, flushes the queued writes (see
+// WithWriteBack), and releases all the items, reporting them to the eviction callback (see
+// WithOnEvict) with EvictClosed reason. After the call, Get and its variants return ErrClosed,
+// and all the other operations have no effect. The method returns the error from flushing
+// the writes, if any.
+func (c *LRU[K, V]) Close() (err error) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.wg.Wait()
+		c.shutdown()
+
+		if c.writeBack > 0 {
+			err = c.Flush(context.Background())
+		}
+	})
+
+	return
+}
+
+// release all the items and mark the cache as closed
+func (c *LRU[K, V]) shutdown() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.closed = true
+
+	for _, node := range c.nodes {
+		c.drop(node, EvictClosed)
 	}
+
+	clear(c.failures)
+	c.closeWatchers()
 }
 
 // Get retrieves the value associated with the given key, invoking backend where necessary.
 func (c *LRU[K, V]) Get(key K) (V, error) {
-	node := c.get(key)
+	return c.getWith(key, c.backend)
+}
 
-	node.once.Do(func() {
-		defer func() {
-			if p := recover(); p != nil {
-				node.err = errors.New("backend function panicked")
-				panic(p)
+// GetWith is the same as Get, but on a cache miss invokes the given loader function instead
+// of the backend of the cache, for example, to pass request-scoped credentials. Concurrent
+// requests for the same key still share one load, by whichever function started it, and the
+// result gets cached as usual. Background reloads (see WithRefreshAhead) always invoke
+// the backend of the cache.
+func (c *LRU[K, V]) GetWith(key K, loader func(K) (V, error)) (V, error) {
+	if loader == nil {
+		panic("attempt to get a value with nil loader function")
+	}
+
+	return c.getWith(key, c.decorate(func(key K) (value V, _ time.Duration, err error) {
+		value, err = loader(key)
+		return
+	}))
+}
+
+// GetWithLoader is the same as GetWith, but the given loader function also returns the TTL
+// of the value, like the backend of a cache created with NewWithTTL.
+func (c *LRU[K, V]) GetWithLoader(key K, loader Loader[K, V]) (V, error) {
+	if loader == nil {
+		panic("attempt to get a value with nil loader function")
+	}
+
+	return c.getWith(key, c.decorate(loader))
+}
+
+// GetWithExpiry is the same as Get, but also returns the time when the value expires, for
+// example, to set HTTP caching headers matching the actual freshness of the data. A time
+// in the past means the value is stale (see WithStaleWhileRevalidate), and the zero time means
+// the value has not been cached at all (see WithErrorPolicy). With WithExpireAfterAccess
+// option, the idle timeout counts from the current request.
+func (c *LRU[K, V]) GetWithExpiry(key K) (value V, expires time.Time, err error) {
+	node := c.acquireLoaded(key, c.backend)
+	value, err = node.value, node.err
+
+	if !node.discard {
+		expires = node.deadline()
+
+		if c.accessTTL > 0 {
+			if idle := c.now().Add(c.accessTTL); idle.Before(expires) {
+				expires = idle
 			}
-		}()
+		}
+	}
+
+	c.release(node)
+	return
+}
+
+// apply the loader middleware to the given function
+func (c *LRU[K, V]) decorate(fn Loader[K, V]) Loader[K, V] {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		fn = c.middleware[i](fn)
+	}
+
+	return fn
+}
+
+// retrieve the value, invoking the given backend function where necessary
+func (c *LRU[K, V]) getWith(key K, backend func(K) (V, time.Duration, error)) (V, error) {
+	node := c.acquireLoaded(key, backend)
+	value, err := node.value, node.err
+
+	c.release(node)
+	return value, err
+}
+
+// acquire the node with its data fetched, invoking the given backend function where necessary;
+// the node must be released after use
+func (c *LRU[K, V]) acquireLoaded(key K, backend func(K) (V, time.Duration, error)) *lruNode[K, V] {
+	if c.hot != nil {
+		c.hot.record(key)
+	}
+
+	if c.freq != nil {
+		c.freq.record(key)
+	}
 
-		node.value, node.err = c.backend(node.key)
+	node := c.lookup(key)
+
+	if node == nil {
+		node = c.get(key)
+	}
+
+	var loaded bool
+
+	node.once.Do(func() {
+		loaded = true
+		c.load(node, backend)
 	})
 
-	return node.value, node.err
+	if !loaded && node.err != nil && errors.Is(node.err, ErrNotFound) {
+		c.stats.negative.Add(1)
+	}
+
+	return node
+}
+
+// fetch data for the node from the given backend function
+func (c *LRU[K, V]) load(node *lruNode[K, V], backend func(K) (V, time.Duration, error)) {
+	defer func() {
+		p := recover()
+
+		if p == nil {
+			return
+		}
+
+		node.err = &PanicError{Value: p, Stack: debug.Stack()}
+
+		if !c.panicRetry {
+			panic(p)
+		}
+
+		// forget the node, so that the next request retries the load
+		c.mu.Lock()
+		defer c.unlock()
+
+		node.ready, node.discard = true, true
+
+		if node.cached() {
+			c.drop(node, evictNone)
+		}
+	}()
+
+	var ttl time.Duration
+
+	c.emit(EventLoadStarted, node.key, evictNone, nil)
+
+	var queued bool
+
+	if c.writeBack > 0 { // the queued write is the most recent value
+		node.value, queued = c.queued(node.key)
+	}
+
+	if !queued {
+		node.value, ttl, node.err = c.fetch(node.key, backend)
+		ttl = max(ttl, 0) // the default TTL instead of a negative one from the backend
+	}
+
+	// the node is created just before loading
+	if node.latency = c.now().Sub(node.ts); !queued {
+		c.stats.latency[latencyBucket(node.latency)].Add(1)
+	}
+
+	c.emit(EventLoadFinished, node.key, evictNone, node.err)
+
+	if node.err != nil {
+		if errTTL := c.errTTL(node.err); errTTL != 0 {
+			ttl = errTTL
+		}
+	}
+
+	var weight int
+
+	if node.err == nil && c.weigher != nil {
+		weight = max(c.weigher(node.key, node.value), 0)
+	}
+
+	var tags []string
+
+	if node.err == nil && c.tagger != nil {
+		tags = c.tagger(node.key, node.value)
+	}
+
+	priority := PriorityNormal
+
+	if node.err == nil {
+		priority = c.priorityOf(node.key, node.value)
+	}
+
+	c.mu.Lock()
+	c.settle(node, ttl, weight, tags, priority)
+	c.unlock()
+}
+
+// time-to-live for the given error: negative if the error is not to be cached,
+// or 0 for the same TTL as for a value
+func (c *LRU[K, V]) errTTL(err error) time.Duration {
+	switch {
+	case refused(err): // not a backend error
+		return -1
+	case c.negative(err):
+		return c.notFoundTTL
+	case c.errorPolicy != nil:
+		if ttl := c.errorPolicy(err); ttl > 0 {
+			return ttl
+		}
+
+		return -1
+	case c.noErrors:
+		return -1
+	default:
+		return c.errorTTL
+	}
+}
+
+// update the node state after the data has been fetched; negative TTL means
+// the node is not to be cached
+func (c *LRU[K, V]) settle(node *lruNode[K, V], ttl time.Duration, weight int, tags []string, priority Priority) {
+	node.ready = true
+
+	if c.failures != nil {
+		ttl = c.errorBackoff(node, ttl)
+	}
+
+	switch {
+	case ttl < 0 || node.discard:
+		node.discard = true
+
+		if node.cached() {
+			c.drop(node, evictNone)
+		}
+
+		return
+	case ttl > 0:
+		node.ttl.Store(int64(c.jittered(ttl)))
+
+		if node.cached() {
+			heap.Fix(&c.expiry, node.index)
+		}
+	}
+
+	node.weight, node.tags = weight, tags
+	c.setPriority(node, priority)
+	c.prioritise(node)
+
+	switch {
+	case node.cached():
+		if node.err == nil {
+			c.deliver(node.key, node.value)
+		}
+
+		c.weight += weight
+		c.tag(node)
+		c.trim()
+	case node.evicted != evictNone && node.err == nil:
+		// the node has been evicted while loading
+		c.notify(node, node.evicted)
+	}
 }
 
 // get or add a cache node
 func (c *LRU[K, V]) get(key K) (node *lruNode[K, V]) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+
+	if c.closed {
+		node = &lruNode[K, V]{key: key, err: ErrClosed, index: -1, ready: true}
+		node.once.Do(func() {})
+		return
+	}
+
+	node = c.find(key)
+
+	c.acquire(node)
+	return
+}
+
+// find or add a cache node (the cache must be locked)
+func (c *LRU[K, V]) find(key K) (node *lruNode[K, V]) {
+	var now time.Time
 
 	node = c.nodes[key]
 
 	switch {
 	case node != nil: // cache hit
-		if time.Since(node.ts) < c.ttl { // happy path
-			node.mtf(&c.list)
+		if c.immortal(node) && c.current(node) { // happy path, without reading the clock
+			c.stats.hits.Add(1)
+			node.hits.Add(1)
+			c.touch(node)
+			return
+		}
+
+		now = c.now()
+
+		if c.fresh(node, now) { // happy path
+			if c.due(node, now) && !node.refreshing && !c.degraded.Load() {
+				node.refreshing = true
+				c.refresh(node)
+			}
+
+			c.stats.hits.Add(1)
+			node.hits.Add(1)
+			node.atime = now
+			c.touch(node)
+			return
+		}
+
+		if !c.current(node) { // invalidated, not expired
+			c.unlink(node, EvictDeleted)
+			break
+		}
+
+		c.stats.expired.Add(1)
+
+		if c.stale(node, now) { // serve the stale value while reloading
+			if !node.refreshing && !c.degraded.Load() {
+				node.refreshing = true
+				c.refresh(node)
+			}
+
+			c.stats.hits.Add(1)
+			node.hits.Add(1)
+			node.atime = now
+			c.touch(node)
 			return
 		}
 
 		// purge the expired node (no need to delete the key)
-		node.purge()
+		c.unlink(node, EvictExpired)
 
 	case len(c.nodes) >= c.size: // cache full
 		// delete the least recent
-		node = (*lruNode[K, V])(unsafe.Pointer(c.list.prev))
-
-		delete(c.nodes, node.key)
-		node.purge()
+		c.evict()
 	}
 
 	// allocate and add a new node as the most recent
-	node = &lruNode[K, V]{key: key, ts: time.Now()}
+	c.stats.misses.Add(1)
+
+	if now.IsZero() {
+		now = c.now()
+	}
+
+	node = c.newNode(key, now)
 
-	node.addTo(&c.list)
-	c.nodes[key] = node
+	if c.door != nil && !c.door.admit(maphash.Comparable(c.door.seed, key)) {
+		// first time seen, so the data is only fetched for the callers
+		c.stats.rejected.Add(1)
+		node.discard = true
+	}
+
+	c.link(node)
+	heap.Push(&c.expiry, node)
+	c.mapNode(node)
 
 	return
 }
 
+// lock-free lookup of a fresh node, only possible under the CLOCK policy where a cache
+// hit does not modify the LRU list, or with buffered promotion where the modifications
+// are deferred; returns nil if the lookup requires locking the cache
+func (c *LRU[K, V]) lookup(key K) *lruNode[K, V] {
+	if !c.lockFree {
+		return nil
+	}
+
+	p, found := c.index.Load(key)
+
+	if !found {
+		return nil
+	}
+
+	node := p.(*lruNode[K, V])
+
+	if !c.current(node) || (!c.immortal(node) && c.now().Sub(node.ts) >= node.lifetime()) {
+		return nil
+	}
+
+	if c.policy == PolicyClock {
+		node.ref.Store(true)
+	} else {
+		c.hits.record(c, node)
+	}
+
+	c.stats.hits.Add(1)
+	node.hits.Add(1)
+	return node
+}
+
+// add the node to the key mapping(s)
+func (c *LRU[K, V]) mapNode(node *lruNode[K, V]) {
+	if c.onAdd != nil && c.nodes[node.key] == nil {
+		c.onAdd(node.key)
+	}
+
+	c.nodes[node.key] = node
+
+	if c.lockFree {
+		c.index.Store(node.key, node)
+	}
+
+	if c.prefixes != nil {
+		c.prefixes.insert(c.keyString(node.key), node.key)
+	}
+
+	if c.ordered != nil {
+		c.ordered.insert(node.key)
+	}
+}
+
+// allocate a new node
+func (c *LRU[K, V]) newNode(key K, now time.Time) *lruNode[K, V] {
+	var node *lruNode[K, V]
+
+	if c.pooled {
+		node = c.pool.Get().(*lruNode[K, V])
+	} else {
+		node = new(lruNode[K, V])
+	}
+
+	node.key, node.ts, node.atime, node.index = key, now, now, -1
+	node.gen = c.gen.Load()
+
+	node.ttl.Store(int64(c.jittered(c.ttl)))
+	return node
+}
+
+// current time
+func (c *LRU[K, V]) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+
+	if now := c.coarse.Load(); now != nil {
+		return *now
+	}
+
+	return time.Now()
+}
+
+// apply random jitter to the given TTL
+func (c *LRU[K, V]) jittered(ttl time.Duration) time.Duration {
+	if c.jitter == 0 || ttl == NoExpiry {
+		return ttl
+	}
+
+	return ttl + time.Duration(float64(ttl)*c.jitter*(2*rand.Float64()-1))
+}
+
+// check if the node has not expired by the given time
+func (c *LRU[K, V]) fresh(node *lruNode[K, V], now time.Time) bool {
+	return now.Sub(node.ts) < node.lifetime() && (c.accessTTL == 0 || now.Sub(node.atime) < c.accessTTL) &&
+		c.current(node)
+}
+
+// check if the node cannot expire by time, so that checking its freshness needs no clock reading
+func (c *LRU[K, V]) immortal(node *lruNode[K, V]) bool {
+	return c.timeless && node.lifetime() == NoExpiry
+}
+
+// check if the node belongs to the current generation (see InvalidateAll)
+func (c *LRU[K, V]) current(node *lruNode[K, V]) bool {
+	return node.gen == c.gen.Load()
+}
+
+// update the position of the node in the LRU list(s) on cache hit (the node must be in the cache)
+func (c *LRU[K, V]) touch(node *lruNode[K, V]) {
+	switch {
+	case node.pinned:
+		return
+	case node.priority != PriorityNormal:
+		node.mtf(c.home(node))
+		return
+	case c.policy == PolicyClock:
+		node.ref.Store(true)
+		return
+	case c.policy == PolicyGreedyDual:
+		node.prio = c.inflation + node.cost
+		node.mtf(&c.list)
+		return
+	case node.protected:
+		node.mtf(&c.protected)
+		return
+	case c.policy != PolicySLRU:
+		node.mtf(&c.list)
+		return
+	}
+
+	// promote the node from probation to the protected segment
+	node.remove()
+	node.addTo(&c.protected)
+	node.protected = true
+
+	if c.numProt++; c.numProt > c.maxProt {
+		// demote the least recent protected node to the top of probation segment
+		demoted := (*lruNode[K, V])(unsafe.Pointer(c.protected.prev))
+
+		demoted.remove()
+		demoted.addTo(&c.list)
+		demoted.protected = false
+		c.numProt--
+	}
+}
+
+// find the node to evict, normally the least recent one, or nil if all the nodes are pinned
+func (c *LRU[K, V]) victim() *lruNode[K, V] {
+	if node := c.tail(&c.low); node != nil {
+		return node
+	}
+
+	if node := c.policyVictim(); node != nil {
+		return node
+	}
+
+	return c.tail(&c.high)
+}
+
+// find the node of normal priority to evict according to the eviction policy, or nil if there
+// is none
+func (c *LRU[K, V]) policyVictim() *lruNode[K, V] {
+	if c.policy == PolicyMRU {
+		if c.list.next == &c.list {
+			return nil
+		}
+
+		return (*lruNode[K, V])(unsafe.Pointer(c.list.next))
+	}
+
+	if c.policy == PolicyGreedyDual {
+		return c.cheapest()
+	}
+
+	if c.list.prev == &c.list { // probation segment is empty
+		if c.protected.prev == &c.protected {
+			return nil
+		}
+
+		return (*lruNode[K, V])(unsafe.Pointer(c.protected.prev))
+	}
+
+	node := (*lruNode[K, V])(unsafe.Pointer(c.list.prev))
+
+	if c.policy == PolicyClock {
+		// give a second chance to the referenced nodes
+		for node.ref.Swap(false) {
+			node.mtf(&c.list)
+			node = (*lruNode[K, V])(unsafe.Pointer(c.list.prev))
+		}
+	}
+
+	return node
+}
+
+// evict the victim node, returning false if there is nothing to evict
+func (c *LRU[K, V]) evict() bool {
+	// the victim is selected by the up-to-date recency
+	c.applyHits()
+
+	node := c.victim()
+
+	if node == nil {
+		return false
+	}
+
+	c.drop(node, EvictCapacity)
+	return true
+}
+
+// evict the victim nodes until the total weight is within the limit
+func (c *LRU[K, V]) trim() {
+	for c.weight > c.maxWeight && c.evict() {
+	}
+}
+
+// delete the node from the cache
+func (c *LRU[K, V]) drop(node *lruNode[K, V], reason EvictReason) {
+	delete(c.nodes, node.key)
+
+	if c.prefixes != nil {
+		c.prefixes.delete(c.keyString(node.key), node.key)
+	}
+
+	if c.ordered != nil {
+		c.ordered.delete(node.key)
+	}
+
+	if c.onDrop != nil {
+		c.onDrop(node.key)
+	}
+
+	// the backoff state survives expiration, as the key is likely to be retried
+	if c.failures != nil && reason != EvictExpired {
+		delete(c.failures, node.key)
+	}
+
+	if c.lockFree {
+		c.index.Delete(node.key)
+	}
+
+	c.unlink(node, reason)
+}
+
+// remove the node from the LRU list(s) and the expiry heap
+func (c *LRU[K, V]) unlink(node *lruNode[K, V], reason EvictReason) {
+	if node.protected {
+		c.numProt--
+	}
+
+	c.weight -= node.weight
+	c.untag(node)
+	node.evicted = reason
+
+	c.count(reason)
+	c.emitEviction(node.key, reason)
+
+	if node.ready && node.err == nil {
+		c.notify(node, reason)
+
+		if reason == EvictDeleted && c.current(node) {
+			var zero V
+
+			c.deliver(node.key, zero)
+		}
+	}
+
+	heap.Remove(&c.expiry, node.index)
+	node.purge()
+	c.retire(node)
+}
+
 // cache node
 type lruNode[K comparable, V any] struct {
 	listNode
 
 	once sync.Once // for locking the node while fetching data
 
-	key   K         // key
-	value V         // value
-	err   error     // error
-	ts    time.Time // timestamp
+	key       K            // key
+	value     V            // value
+	err       error        // error
+	ts        time.Time    // timestamp
+	atime     time.Time    // last access time
+	ttl       atomic.Int64 // time-to-live (time.Duration)
+	protected bool         // true if the node is in the protected segment
+	pinned    bool         // true if the node is in the list of pinned nodes
+	weight    int          // weight of the item
+	tags      []string     // tags of the item
+	ref       atomic.Bool  // reference bit for CLOCK policy
+	priority  Priority     // eviction priority
+	cost      float64      // cost of the item per unit of weight (GreedyDual only)
+	prio      float64      // eviction priority (GreedyDual only)
+	hits      atomic.Int64 // number of cache hits on the node
+	refs      atomic.Int32 // number of references to the node (when pooled)
+	index     int          // index in the expiry heap (-1 if not in the heap)
+	gen       uint64       // generation of the node
+
+	evicted EvictReason   // reason for removal from the cache
+	latency time.Duration // time taken to fetch the data
+
+	ready      bool // true when the data has been fetched
+	refreshing bool // true while the node is being reloaded in the background
+	discard    bool // true if the fetched data is not to be cached
+}
+
+// time-to-live of the node
+func (node *lruNode[K, V]) lifetime() time.Duration {
+	return time.Duration(node.ttl.Load())
+}
+
+// check if the node is currently in the cache
+func (node *lruNode[K, V]) cached() bool {
+	return node.index >= 0
 }
 
 // LRU list
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -166,6 +167,951 @@ func TestCacheOperation(t *testing.T) {
 	}
 }
 
+func TestSLRU(t *testing.T) {
+	var (
+		backend tracingBackend
+		err     error
+	)
+
+	c := New(5, time.Hour, backend.fn, WithPolicy(PolicySLRU))
+
+	if err = fill(c.Get, []int{1, 2, 3, 4, 5, 1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// probation: {3, 4, 5}, protected: {1, 2}
+	if err = matchTraces(listKeys(&c.list), []int{3, 4, 5}); err != nil {
+		t.Error("invalid probation segment:", err)
+		return
+	}
+
+	if err = matchTraces(listKeys(&c.protected), []int{1, 2}); err != nil {
+		t.Error("invalid protected segment:", err)
+		return
+	}
+
+	// one-shot keys must not evict the protected ones
+	if err = fill(c.Get, []int{6, 7, 8, 9, 1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err = matchTraces(listKeys(&c.list), []int{7, 8, 9}); err != nil {
+		t.Error("invalid probation segment:", err)
+		return
+	}
+
+	if err = matchTraces(listKeys(&c.protected), []int{1, 2}); err != nil {
+		t.Error("invalid protected segment:", err)
+		return
+	}
+
+	// overflow of the protected segment demotes its least recent node
+	if err = fill(c.Get, []int{7, 8, 9}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err = matchTraces(listKeys(&c.list), []int{1}); err != nil {
+		t.Error("invalid probation segment:", err)
+		return
+	}
+
+	if err = matchTraces(listKeys(&c.protected), []int{2, 7, 8, 9}); err != nil {
+		t.Error("invalid protected segment:", err)
+		return
+	}
+
+	c.Delete(8)
+
+	if c.numProt != 3 || len(c.nodes) != 4 {
+		t.Errorf("unexpected cache size after delete: %d protected, %d total", c.numProt, len(c.nodes))
+		return
+	}
+
+	if err = matchTraces(backend.trace, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestClock(t *testing.T) {
+	var (
+		backend tracingBackend
+		err     error
+	)
+
+	c := New(4, time.Hour, backend.fn, WithPolicy(PolicyClock))
+
+	if err = fill(c.Get, []int{1, 2, 3, 4, 1, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// hits do not change the order
+	if err = checkState(c, []int{1, 2, 3, 4}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	// the referenced nodes get a second chance
+	if err = fill(c.Get, []int{5, 6}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err = checkState(c, []int{1, 5, 3, 6}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	// now all the reference bits are clear
+	if err = fill(c.Get, []int{7}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err = checkState(c, []int{5, 3, 6, 7}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	if err = matchTraces(backend.trace, []int{1, 2, 3, 4, 5, 6, 7}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestLockFreeHits(t *testing.T) {
+	var (
+		backend intBackendMT
+		wg      sync.WaitGroup
+	)
+
+	c := New(50, time.Hour, backend.fn, WithPolicy(PolicyClock))
+
+	if !c.lockFree {
+		t.Error("lock-free hits are not enabled")
+		return
+	}
+
+	wg.Add(4)
+
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10000; j++ {
+				k := rand.Intn(60)
+
+				if rand.Intn(100) == 0 {
+					c.Delete(k)
+				} else if err := getOne(c, k); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// the index must mirror the key mapping
+	n := 0
+
+	c.index.Range(func(k, p any) bool {
+		if n++; c.nodes[k.(int)] != p.(*lruNode[int, int]) {
+			t.Errorf("index mismatch for key %d", k)
+			return false
+		}
+
+		return true
+	})
+
+	if n != len(c.nodes) {
+		t.Errorf("unexpected index size: %d instead of %d", n, len(c.nodes))
+		return
+	}
+}
+
+func TestPerItemTTL(t *testing.T) {
+	var backend tracingBackend
+
+	c := NewWithTTL(10, time.Hour, func(key int) (int, time.Duration, error) {
+		v, err := backend.fn(key)
+
+		switch {
+		case key%2 != 0:
+			return v, time.Millisecond, err
+		case key == 4:
+			return v, -time.Minute, err // same as 0
+		default:
+			return v, 0, err
+		}
+	})
+
+	if err := fill(c.Get, []int{1, 2, 3, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if err := fill(c.Get, []int{1, 2, 3, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 3, 4, 1, 3}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestExpireAfterAccess(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn, WithExpireAfterAccess(100*time.Millisecond))
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// keep key 1 hot
+	for i := 0; i < 5; i++ {
+		time.Sleep(30 * time.Millisecond)
+
+		if err := getOne(c, 1); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 2}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestErrorTTL(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn, WithErrorTTL(time.Millisecond))
+
+	if err := fill(c.Get, []int{1, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if err := fill(c.Get, []int{1, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 1000, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestNoErrorCaching(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn, WithNoErrorCaching())
+
+	if err := fill(c.Get, []int{1, 1000, 1, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{1}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 1000, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestErrorPolicy(t *testing.T) {
+	var backend tracingBackend
+
+	errNotFound := errors.New("not found")
+
+	c := New(10, time.Hour, func(key int) (int, error) {
+		v, err := backend.fn(key)
+
+		if err != nil && key > 1000 {
+			err = errNotFound
+		}
+
+		return v, err
+	}, WithErrorPolicy(func(err error) time.Duration {
+		if errors.Is(err, errNotFound) {
+			return time.Hour
+		}
+
+		return 0
+	}))
+
+	if err := fill(c.Get, []int{1, 1000, 1001, 1, 1000, 1001}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{1, 1001}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 1000, 1001, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestNotFoundTTL(t *testing.T) {
+	var trace []int
+
+	now := time.Now()
+
+	c := New(10, time.Hour, func(key int) (int, error) {
+		trace = append(trace, key)
+
+		if key == 1 {
+			return 0, fmt.Errorf("key %d: %w", key, ErrNotFound)
+		}
+
+		return 0, errors.New("backend failure")
+	}, WithNoErrorCaching(), WithNotFoundTTL(time.Minute), WithClock(func() time.Time { return now }))
+
+	get := func() {
+		for _, key := range []int{1, 1, 2, 2} {
+			c.Get(key)
+		}
+	}
+
+	get()
+
+	now = now.Add(2 * time.Minute)
+
+	get()
+
+	if err := matchTraces(trace, []int{1, 2, 2, 1, 2, 2}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if _, err := c.Get(1); !errors.Is(err, ErrNotFound) {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if s := c.Stats(); s.NotFound != 2 || s.NegativeHits != 3 || s.LoadErrors != 4 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+}
+
+func TestPanicRetry(t *testing.T) {
+	for _, retry := range []bool{false, true} {
+		var calls atomic.Int32
+
+		backend := func(key int) (int, error) {
+			if calls.Add(1) == 1 {
+				panic("oops")
+			}
+
+			return simpleBackend(key)
+		}
+
+		var opts []Option
+
+		if retry {
+			opts = append(opts, WithPanicRetry())
+		}
+
+		c := New(10, time.Hour, backend, opts...)
+
+		// the first load panics
+		_, err := func() (_ int, err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = fmt.Errorf("panic: %v", p)
+				}
+			}()
+
+			return c.Get(1)
+		}()
+
+		var pe *PanicError
+
+		switch {
+		case !retry && (err == nil || errors.As(err, &pe)):
+			t.Error("missing panic, got", err)
+			return
+		case retry && (!errors.As(err, &pe) || pe.Value != "oops" || len(pe.Stack) == 0):
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		// the next request either retries the load, or gets the cached panic
+		v, err := c.Get(1)
+
+		switch {
+		case retry && (err != nil || v != -1):
+			t.Error("unexpected result after retry:", v, err)
+			return
+		case !retry && !errors.As(err, &pe):
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+}
+
+func TestWeigher(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(100, time.Hour, backend.fn, WithWeigher(10, func(k, _ int) int { return k }))
+
+	if err := fill(c.Get, []int{1, 2, 3, 4, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{1, 2, 3, 4, 1000}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	if err := fill(c.Get, []int{5}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{4, 1000, 5}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	if c.weight != 9 {
+		t.Errorf("unexpected total weight: %d instead of 9", c.weight)
+		return
+	}
+
+	// an item heavier than the limit is not cached
+	if err := fill(c.Get, []int{11}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := assertEmpty(c); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+}
+
+func TestTTLJitter(t *testing.T) {
+	const ttl = time.Hour
+
+	c := New(100, ttl, simpleBackend, WithTTLJitter(0.1))
+
+	if err := fill(c.Get, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	ttls := make(map[time.Duration]bool, len(c.nodes))
+
+	for k, node := range c.nodes {
+		if d := node.lifetime(); d < ttl*9/10 || d > ttl*11/10 {
+			t.Errorf("TTL of key %d is out of range: %s", k, d)
+			return
+		}
+
+		ttls[node.lifetime()] = true
+	}
+
+	if len(ttls) < 2 {
+		t.Error("TTL jitter has not been applied")
+		return
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	var published []int
+
+	replica := New(10, time.Hour, simpleBackend)
+	c := New(10, time.Hour, simpleBackend, WithInvalidationPublisher(func(key int) {
+		published = append(published, key)
+		replica.ApplyInvalidation(key)
+	}))
+
+	for _, cache := range []*LRU[int, int]{c, replica} {
+		if err := fill(cache.Get, []int{1, 2, 3}, validKey); err != nil {
+			t.Error("error filling the cache:", err)
+			return
+		}
+	}
+
+	c.Delete(2)
+	c.Delete(5)
+
+	if fmt.Sprint(published) != "[2 5]" {
+		t.Error("unexpected invalidations:", published)
+		return
+	}
+
+	for _, cache := range []*LRU[int, int]{c, replica} {
+		if len(cache.nodes) != 2 || cache.nodes[2] != nil {
+			t.Error("the key has not been invalidated")
+			return
+		}
+	}
+}
+
+func TestInvalidateAll(t *testing.T) {
+	for _, policy := range []Policy{PolicyLRU, PolicyClock} {
+		var backend tracingBackend
+
+		c := New(3, time.Hour, backend.fn, WithPolicy(policy))
+
+		if err := fill(c.Get, []int{1, 2, 3}, validKey); err != nil {
+			t.Error("error filling the cache:", err)
+			return
+		}
+
+		c.InvalidateAll()
+
+		if _, found := c.Info(1); found {
+			t.Error("unexpected info for an invalidated key")
+			return
+		}
+
+		// the invalidated items are reloaded on access, or evicted
+		if err := fill(c.Get, []int{1, 4, 1, 2}, validKey); err != nil {
+			t.Error("error reading the cache:", err)
+			return
+		}
+
+		if err := matchTraces(backend.trace, []int{1, 2, 3, 1, 4, 2}); err != nil {
+			t.Error("invalid trace:", err)
+			return
+		}
+
+		if s := c.Stats(); s.EvictedDeleted+s.EvictedCapacity != 3 || s.ExpiredHits != 0 {
+			t.Errorf("unexpected stats: %+v", s)
+			return
+		}
+	}
+}
+
+func TestMRU(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(3, time.Hour, backend.fn, WithPolicy(PolicyMRU))
+
+	// cyclic scan over more keys than the cache holds
+	if err := fill(c.Get, []int{1, 2, 3, 4, 1, 2, 3, 4}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 3, 4, 3}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if err := checkState(c, []int{1, 3, 4}, validKey); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if s := c.Stats(); s.Hits != 3 || s.EvictedCapacity != 2 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+}
+
+func TestSizeOne(t *testing.T) {
+	for _, policy := range []Policy{PolicyLRU, PolicySLRU, PolicyClock, PolicyMRU, PolicyGreedyDual} {
+		var backend tracingBackend
+
+		c := New(1, time.Hour, backend.fn, WithPolicy(policy))
+
+		if err := fill(c.Get, []int{1, 1, 2, 2, 1}, validKey); err != nil {
+			t.Error("error reading the cache:", err)
+			return
+		}
+
+		if err := matchTraces(backend.trace, []int{1, 2, 1}); err != nil {
+			t.Error("invalid trace:", err)
+			return
+		}
+	}
+}
+
+func TestUnbounded(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(2, time.Hour, backend.fn, WithUnbounded())
+
+	keys := make([]int, 100)
+
+	for i := range keys {
+		keys[i] = i
+	}
+
+	if err := fill(c.Get, append(keys, keys...), validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, keys); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if s := c.Stats(); s.Size != len(keys) || s.Capacity != 0 || s.EvictedCapacity != 0 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+
+	_, err := TryNew(10, time.Hour, simpleBackend, WithUnbounded(),
+		WithAdaptiveCapacity(5, 20, 0.8, time.Hour))
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Error("unexpected error for adaptive capacity:", err)
+		return
+	}
+}
+
+func TestNoExpiry(t *testing.T) {
+	for _, ttl := range []time.Duration{0, NoExpiry} {
+		c := New(10, ttl, simpleBackend, WithTTLJitter(0.5))
+
+		_, expires, err := c.GetWithExpiry(1)
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		if !c.timeless {
+			t.Error("no fast path for TTL", ttl)
+			return
+		}
+
+		if limit := time.Now().Add(100 * 365 * 24 * time.Hour); expires.Before(limit) {
+			t.Error("unexpected expiration time:", expires)
+			return
+		}
+	}
+}
+
+func TestDeleteAndWait(t *testing.T) {
+	var calls atomic.Int32
+
+	started, proceed := make(chan struct{}), make(chan struct{})
+
+	c := New(10, time.Hour, func(key int) (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-proceed
+		}
+
+		return simpleBackend(key)
+	})
+
+	res := make(chan error, 1)
+
+	go func() {
+		res <- fill(c.Get, []int{1}, validKey)
+	}()
+
+	<-started
+
+	deleted := make(chan struct{})
+
+	go func() {
+		c.DeleteAndWait(1)
+		close(deleted)
+	}()
+
+	select {
+	case <-deleted:
+		t.Error("deletion has not waited for the load")
+		return
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(proceed)
+	<-deleted
+
+	// the waiting caller gets the value, but it is not cached
+	if err := <-res; err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if n := c.Len(); n != 0 {
+		t.Error("unexpected number of items:", n)
+		return
+	}
+
+	if err := fill(c.Get, []int{1}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Error("unexpected number of backend calls:", n)
+		return
+	}
+}
+
+func TestNoTTLClock(t *testing.T) {
+	for _, policy := range []Policy{PolicyLRU, PolicyClock} {
+		var reads atomic.Int64
+
+		c := New(3, 0, func(key int) (int, error) { return -key, nil },
+			WithPolicy(policy),
+			WithClock(func() time.Time {
+				reads.Add(1)
+				return time.Now()
+			}))
+
+		if err := fill(c.Get, []int{1, 2, 3}, validKey); err != nil {
+			t.Error("error filling the cache:", err)
+			return
+		}
+
+		n := reads.Load()
+
+		// cache hits need no clock
+		if err := fill(c.Get, []int{1, 2, 3, 3, 2, 1}, validKey); err != nil {
+			t.Error("error reading the cache:", err)
+			return
+		}
+
+		if m := reads.Load(); m != n {
+			t.Errorf("unexpected number of clock reads on cache hits: %d instead of %d", m, n)
+			return
+		}
+
+		if s := c.Stats(); s.Hits != 6 || s.Misses != 3 {
+			t.Errorf("unexpected stats: %+v", s)
+			return
+		}
+	}
+}
+
+func TestGetWith(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn)
+
+	v, err := c.GetWith(1, func(key int) (int, error) { return key * 10, nil })
+
+	if err != nil || v != 10 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	// the value is cached
+	if v, err = c.Get(1); err != nil || v != 10 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	if len(backend.trace) != 0 {
+		t.Error("unexpected backend calls:", backend.trace)
+		return
+	}
+
+	// a cached value is returned without invoking the loader
+	if v, err = c.GetWith(1, func(int) (int, error) { return 0, errors.New("unexpected call") }); err != nil || v != 10 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+}
+
+func TestGetWithLoader(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend)
+
+	v, err := c.GetWithLoader(1, func(key int) (int, time.Duration, error) { return key * 10, time.Minute, nil })
+
+	if err != nil || v != 10 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	if ttl, ok := c.TTL(1); !ok || ttl > time.Minute {
+		t.Error("unexpected TTL:", ttl, ok)
+		return
+	}
+}
+
+func TestGetWithExpiry(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := now
+
+	c := New(10, time.Hour, simpleBackend,
+		WithClock(func() time.Time { return now }),
+		WithExpireAfterAccess(10*time.Minute),
+		WithErrorPolicy(func(error) time.Duration { return 0 }))
+
+	// the idle timeout counts from the last access, within the total lifetime
+	for i := 0; i < 8; i++ {
+		_, expires, err := c.GetWithExpiry(1)
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		exp := now.Add(10 * time.Minute)
+
+		if i == 7 {
+			exp = start.Add(time.Hour)
+		}
+
+		if !expires.Equal(exp) {
+			t.Errorf("unexpected expiration time: %s instead of %s", expires, exp)
+			return
+		}
+
+		now = now.Add(8 * time.Minute)
+	}
+
+	// errors not cached
+	if _, expires, err := c.GetWithExpiry(1000); err == nil || !expires.IsZero() {
+		t.Error("unexpected result:", expires, err)
+		return
+	}
+}
+
+func TestLoaderMiddleware(t *testing.T) {
+	var log []string
+
+	logger := func(name string) func(Loader[int, int]) Loader[int, int] {
+		return func(next Loader[int, int]) Loader[int, int] {
+			return func(key int) (int, time.Duration, error) {
+				log = append(log, fmt.Sprintf("%s:%d", name, key))
+				return next(key)
+			}
+		}
+	}
+
+	c := New(10, time.Hour, simpleBackend, WithLoaderMiddleware(logger("a")), WithLoaderMiddleware(logger("b")))
+
+	if err := fill(c.Get, []int{1, 1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if _, err := c.GetWith(3, simpleBackend); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err := matchLogs(log, []string{"a:1", "b:1", "a:2", "b:2", "a:3", "b:3"}); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestInjectedClock(t *testing.T) {
+	var (
+		backend tracingBackend
+		mu      sync.Mutex
+		now     = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now = now.Add(d)
+	}
+
+	c := New(10, time.Hour, backend.fn, WithClock(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return now
+	}))
+
+	for _, d := range []time.Duration{0, 59 * time.Minute, 2 * time.Minute} {
+		advance(d)
+
+		if err := getOne(c, 1); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 1}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if ts := c.nodes[1].ts; !ts.Equal(time.Date(2000, 1, 1, 1, 1, 0, 0, time.UTC)) {
+		t.Error("unexpected timestamp:", ts)
+		return
+	}
+}
+
+func TestClose(t *testing.T) {
+	var evicted int
+
+	c := New(10, time.Hour, simpleBackend, WithJanitor(time.Hour), WithOnEvict(func(_, _ int, reason EvictReason) {
+		if reason == EvictClosed {
+			evicted++
+		}
+	}))
+
+	if err := fill(c.Get, []int{1, 2, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.Close()
+	c.Close()
+
+	if err := assertEmpty(c); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := c.Get(1); err != ErrClosed {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// the items are released to the callback
+	if evicted != 3 || len(c.nodes) != 0 {
+		t.Error("unexpected state after closing:", evicted, len(c.nodes))
+		return
+	}
+
+	if s := c.Stats(); s.EvictedClosed != 3 || s.Evictions() != 3 {
+		t.Errorf("unexpected number of items released: %d of %d", s.EvictedClosed, s.Evictions())
+		return
+	}
+}
+
 func TestRandomFill(t *testing.T) {
 	var (
 		backend tracingBackend
@@ -327,6 +1273,45 @@ func BenchmarkCache(b *testing.B) {
 	}
 }
 
+func BenchmarkCacheNoTTL(b *testing.B) {
+	const cacheSize = 100
+
+	c := New(cacheSize, 0, simpleBackend)
+
+	// warm-up
+	for k := 0; k < cacheSize; k++ {
+		if err := getOne(c, k); err != nil {
+			b.Error(err)
+			return
+		}
+	}
+
+	// run
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := getOne(c, i%cacheSize); err != nil {
+			b.Error(err)
+			return
+		}
+	}
+}
+
+func BenchmarkCacheClock(b *testing.B) {
+	const cacheSize = 100
+
+	c := New(cacheSize, time.Hour, simpleBackend, WithPolicy(PolicyClock))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if err := getOne(c, i%cacheSize); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
 const benchCacheSize = 1000
 
 func BenchmarkContended_1(b *testing.B) {
@@ -0,0 +1,10 @@
+module github.com/maxim2266/cache/memcachestore
+
+go 1.24
+
+require (
+	github.com/bradfitz/gomemcache v0.0.0-20260422231931-4d751bb6e37c
+	github.com/maxim2266/cache v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/maxim2266/cache => ..
@@ -0,0 +1,134 @@
+// Package memcachestore provides memcached implementation of the second-level cache store.
+package memcachestore
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/maxim2266/cache"
+)
+
+// Store is an implementation of cache.Store interface on top of memcached.
+type Store[K comparable, V any] struct {
+	client *memcache.Client  // memcached client
+	codec  cache.Codec[K, V] // codec for the values
+	prefix string            // prefix for all memcached keys
+}
+
+// New creates a new memcached store with keys of type "K" and values of type "V", using
+// the given client. Each cache key is mapped to the memcached key by formatting it with
+// fmt.Sprint and adding the given prefix (or by hashing, if the result is not a valid
+// memcached key), and each cache entry is stored as an item encoded by the given codec.
+// For consistent hashing of the keys across servers, create the client with a Ring selector.
+func New[K comparable, V any](client *memcache.Client, codec cache.Codec[K, V], prefix string) *Store[K, V] {
+	if client == nil {
+		panic("attempt to create a memcached store with nil client")
+	}
+
+	if codec == nil {
+		panic("attempt to create a memcached store with nil codec")
+	}
+
+	return &Store[K, V]{client, codec, prefix}
+}
+
+// Get implements cache.Store interface. The context is not used, as the memcached client
+// does not support it.
+func (s *Store[K, V]) Get(_ context.Context, key K) (value V, found bool, err error) {
+	item, err := s.client.Get(s.key(key))
+
+	switch err {
+	case nil:
+		// ok
+	case memcache.ErrCacheMiss:
+		return value, false, nil
+	default:
+		return
+	}
+
+	var e cache.Entry[K, V]
+
+	switch err = s.codec.Decode(item.Value, &e); err {
+	case nil:
+		return e.Value, true, nil
+	case cache.ErrSkipEntry:
+		return value, false, nil
+	default:
+		return
+	}
+}
+
+// Set implements cache.Store interface. The item expires after the given TTL, rounded up to
+// a whole number of seconds. An entry rejected by the codec with cache.ErrSkipEntry is not
+// stored.
+func (s *Store[K, V]) Set(_ context.Context, key K, value V, ttl time.Duration) error {
+	now := time.Now()
+
+	data, err := s.codec.Encode(&cache.Entry[K, V]{Key: key, Value: value, Time: now, Expires: now.Add(ttl)})
+
+	switch err {
+	case nil:
+		return s.client.Set(&memcache.Item{Key: s.key(key), Value: data, Expiration: expiration(ttl, now)})
+	case cache.ErrSkipEntry:
+		return nil
+	default:
+		return err
+	}
+}
+
+// Delete implements cache.Store interface.
+func (s *Store[K, V]) Delete(_ context.Context, key K) error {
+	if err := s.client.Delete(s.key(key)); err != memcache.ErrCacheMiss {
+		return err
+	}
+
+	return nil
+}
+
+// memcached key for the given cache key
+func (s *Store[K, V]) key(key K) string {
+	if k := s.prefix + fmt.Sprint(key); legalKey(k) {
+		return k
+	}
+
+	h := sha1.Sum([]byte(fmt.Sprint(key)))
+
+	return s.prefix + "#" + hex.EncodeToString(h[:])
+}
+
+// check if the key can be used with memcached
+func legalKey(key string) bool {
+	if len(key) > 250 {
+		return false
+	}
+
+	for i := range len(key) {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
+
+// max. relative expiration time; memcached treats larger values as absolute Unix time
+const maxRelative = 30 * 24 * 60 * 60
+
+// memcached expiration time for the given TTL
+func expiration(ttl time.Duration, now time.Time) int32 {
+	if ttl <= 0 {
+		return 0 // never expires
+	}
+
+	secs := int64((ttl + time.Second - 1) / time.Second)
+
+	if secs > maxRelative {
+		secs += now.Unix()
+	}
+
+	return int32(secs)
+}
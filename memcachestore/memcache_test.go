@@ -0,0 +1,220 @@
+package memcachestore
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/maxim2266/cache"
+)
+
+func TestStore(t *testing.T) {
+	srv, err := startServer(t)
+
+	if err != nil {
+		t.Error("error starting the server:", err)
+		return
+	}
+
+	ring, err := NewRing(srv.addr)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	ctx := context.Background()
+	store := New(memcache.NewFromSelector(ring), cache.JSONCodec[string, int]{}, "test:")
+
+	if _, found, err := store.Get(ctx, "a"); err != nil || found {
+		t.Error("unexpected result:", found, err)
+		return
+	}
+
+	if err := store.Set(ctx, "a", 1, 1500*time.Millisecond); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if v, found, err := store.Get(ctx, "a"); err != nil || !found || v != 1 {
+		t.Error("unexpected result:", v, found, err)
+		return
+	}
+
+	if exp := srv.expiration("test:a"); exp != 2 {
+		t.Errorf("unexpected expiration: %d instead of 2", exp)
+		return
+	}
+
+	// long and invalid keys get hashed
+	key := strings.Repeat("x y", 100)
+
+	if err := store.Set(ctx, key, 2, time.Minute); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if v, found, err := store.Get(ctx, key); err != nil || !found || v != 2 {
+		t.Error("unexpected result:", v, found, err)
+		return
+	}
+
+	// deletion, including a missing key
+	for _, k := range []string{"a", "a"} {
+		if err := store.Delete(ctx, k); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	if _, found, err := store.Get(ctx, "a"); err != nil || found {
+		t.Error("unexpected result:", found, err)
+		return
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		ttl time.Duration
+		exp int32
+	}{
+		{0, 0},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{time.Hour, 3600},
+		{40 * 24 * time.Hour, 1000 + 40*24*3600},
+	}
+
+	for _, test := range tests {
+		if exp := expiration(test.ttl, now); exp != test.exp {
+			t.Errorf("%s: unexpected expiration %d instead of %d", test.ttl, exp, test.exp)
+			return
+		}
+	}
+}
+
+// minimal memcached server supporting "gets", "set" and "delete" commands
+type server struct {
+	addr string
+	mu   sync.Mutex
+	data map[string]item
+}
+
+type item struct {
+	value []byte
+	exp   int
+}
+
+func startServer(t *testing.T) (*server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	srv := &server{addr: l.Addr().String(), data: make(map[string]item)}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go srv.serve(conn)
+		}
+	}()
+
+	return srv, nil
+}
+
+func (srv *server) expiration(key string) int {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	return srv.data[key].exp
+}
+
+func (srv *server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+
+	for {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			return
+		}
+
+		if err = srv.handle(strings.Fields(line), r, w); err != nil {
+			return
+		}
+
+		if err = w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (srv *server) handle(cmd []string, r *bufio.Reader, w io.Writer) (err error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	switch {
+	case len(cmd) > 1 && cmd[0] == "gets":
+		for _, key := range cmd[1:] {
+			if it, ok := srv.data[key]; ok {
+				fmt.Fprintf(w, "VALUE %s 0 %d 1\r\n%s\r\n", key, len(it.value), it.value)
+			}
+		}
+
+		_, err = io.WriteString(w, "END\r\n")
+
+	case len(cmd) == 5 && cmd[0] == "set":
+		var exp, n int
+
+		if exp, err = strconv.Atoi(cmd[3]); err != nil {
+			return
+		}
+
+		if n, err = strconv.Atoi(cmd[4]); err != nil {
+			return
+		}
+
+		value := make([]byte, n+2)
+
+		if _, err = io.ReadFull(r, value); err != nil {
+			return
+		}
+
+		srv.data[cmd[1]] = item{value[:n], exp}
+		_, err = io.WriteString(w, "STORED\r\n")
+
+	case len(cmd) == 2 && cmd[0] == "delete":
+		if _, ok := srv.data[cmd[1]]; ok {
+			delete(srv.data, cmd[1])
+			_, err = io.WriteString(w, "DELETED\r\n")
+		} else {
+			_, err = io.WriteString(w, "NOT_FOUND\r\n")
+		}
+
+	default:
+		_, err = io.WriteString(w, "ERROR\r\n")
+	}
+
+	return
+}
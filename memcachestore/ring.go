@@ -0,0 +1,58 @@
+package memcachestore
+
+import (
+	"net"
+	"strings"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/maxim2266/cache/internal/ring"
+)
+
+// Ring is a memcache.ServerSelector that maps keys to servers using consistent hashing,
+// so that adding or removing a server only remaps the keys of that server.
+type Ring struct {
+	addrs []net.Addr // servers
+	ring  *ring.Ring // consistent hashing
+}
+
+// NewRing creates a new consistent hashing ring over the given servers, each specified either
+// as "host:port" or as a path to a Unix socket.
+func NewRing(servers ...string) (*Ring, error) {
+	addrs := make([]net.Addr, len(servers))
+
+	for i, server := range servers {
+		var err error
+
+		if strings.Contains(server, "/") {
+			addrs[i], err = net.ResolveUnixAddr("unix", server)
+		} else {
+			addrs[i], err = net.ResolveTCPAddr("tcp", server)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Ring{addrs, ring.New(servers...)}, nil
+}
+
+// PickServer implements memcache.ServerSelector interface.
+func (r *Ring) PickServer(key string) (net.Addr, error) {
+	if len(r.addrs) == 0 {
+		return nil, memcache.ErrNoServers
+	}
+
+	return r.addrs[r.ring.Pick(key)], nil
+}
+
+// Each implements memcache.ServerSelector interface.
+func (r *Ring) Each(fn func(net.Addr) error) error {
+	for _, addr := range r.addrs {
+		if err := fn(addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package memcachestore
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	servers := []string{"127.0.0.1:11211", "127.0.0.1:11212", "127.0.0.1:11213"}
+	r3, err := NewRing(servers...)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	r4, err := NewRing(append(servers, "127.0.0.1:11214")...)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	const numKeys = 10000
+
+	counts := make(map[string]int)
+	moved := 0
+
+	for i := range numKeys {
+		key := "key-" + strconv.Itoa(i)
+
+		a3, err := r3.PickServer(key)
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		a4, err := r4.PickServer(key)
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		counts[a3.String()]++
+
+		if a3.String() != a4.String() {
+			if moved++; a4.String() != "127.0.0.1:11214" {
+				t.Errorf("key %q moved between old servers", key)
+				return
+			}
+		}
+	}
+
+	// roughly even distribution
+	for addr, n := range counts {
+		if n < numKeys/5 || n > numKeys/2 {
+			t.Errorf("uneven distribution: %d keys on %s", n, addr)
+			return
+		}
+	}
+
+	// about a quarter of the keys move to the new server
+	if moved < numKeys/8 || moved > numKeys/2 {
+		t.Errorf("unexpected number of moved keys: %d", moved)
+		return
+	}
+
+	// iteration
+	var addrs []net.Addr
+
+	r3.Each(func(addr net.Addr) error {
+		addrs = append(addrs, addr)
+		return nil
+	})
+
+	if len(addrs) != len(servers) {
+		t.Errorf("unexpected number of servers: %d instead of %d", len(addrs), len(servers))
+		return
+	}
+
+	// empty ring
+	if _, err = new(Ring).PickServer("x"); err == nil {
+		t.Error("missing error")
+		return
+	}
+}
@@ -0,0 +1,54 @@
+// Package expvar publishes cache statistics via the standard expvar package, without any
+// third-party dependencies.
+package expvar
+
+import (
+	"expvar"
+
+	"github.com/maxim2266/cache"
+)
+
+// StatsSource is the interface to any cache that provides statistics.
+type StatsSource interface {
+	Stats() cache.Stats
+}
+
+// Publish publishes statistics of the given cache as an expvar variable with the given name,
+// rendered as a JSON object. Like expvar.Publish, the function panics if the name is already
+// registered.
+func Publish(name string, src StatsSource) {
+	expvar.Publish(name, expvar.Func(func() any {
+		s := src.Stats()
+
+		return expvarStats{
+			Size:        s.Size,
+			Weight:      s.Weight,
+			Hits:        s.Hits,
+			Misses:      s.Misses,
+			ExpiredHits: s.ExpiredHits,
+			HitRatio:    s.HitRatio(),
+			Loads:       s.Loads,
+			LoadErrors:  s.LoadErrors,
+			Evictions: map[string]uint64{
+				"capacity": s.EvictedCapacity,
+				"expired":  s.EvictedExpired,
+				"deleted":  s.EvictedDeleted,
+				"replaced": s.EvictedReplaced,
+				"closed":   s.EvictedClosed,
+			},
+		}
+	}))
+}
+
+// JSON representation of cache statistics
+type expvarStats struct {
+	Size        int               `json:"size"`
+	Weight      int               `json:"weight"`
+	Hits        uint64            `json:"hits"`
+	Misses      uint64            `json:"misses"`
+	ExpiredHits uint64            `json:"expired_hits"`
+	HitRatio    float64           `json:"hit_ratio"`
+	Loads       uint64            `json:"loads"`
+	LoadErrors  uint64            `json:"load_errors"`
+	Evictions   map[string]uint64 `json:"evictions"`
+}
@@ -0,0 +1,42 @@
+package expvar
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/maxim2266/cache"
+)
+
+func TestPublish(t *testing.T) {
+	c := cache.New(10, time.Hour, func(key int) (int, error) { return -key, nil })
+
+	for _, k := range []int{1, 2, 1, 1} {
+		if _, err := c.Get(k); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	Publish("test_cache", c)
+
+	v := expvar.Get("test_cache")
+
+	if v == nil {
+		t.Error("the variable has not been published")
+		return
+	}
+
+	var s expvarStats
+
+	if err := json.Unmarshal([]byte(v.String()), &s); err != nil {
+		t.Error("invalid JSON:", err)
+		return
+	}
+
+	if s.Size != 2 || s.Hits != 2 || s.Misses != 2 || s.Loads != 2 || s.HitRatio != 0.5 {
+		t.Errorf("unexpected statistics: %+v", s)
+		return
+	}
+}
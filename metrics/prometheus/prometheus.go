@@ -0,0 +1,76 @@
+// Package prometheus provides a Prometheus collector exposing cache statistics.
+package prometheus
+
+import (
+	"github.com/maxim2266/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsSource is the interface to any cache that provides statistics.
+type StatsSource interface {
+	Stats() cache.Stats
+}
+
+// NewCollector creates a Prometheus collector exposing statistics of the given cache,
+// with the given constant labels (e.g., cache name and shard number) attached to every
+// metric. Multiple collectors with the same label names and different label values can be
+// registered to monitor multiple caches.
+func NewCollector(src StatsSource, labels prometheus.Labels) prometheus.Collector {
+	desc := func(name, help string, varLabels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("cache", "", name), help, varLabels, labels)
+	}
+
+	return &collector{
+		src:         src,
+		hits:        desc("hits_total", "Number of requests served from the cache."),
+		misses:      desc("misses_total", "Number of requests that required fetching data from the backend."),
+		expiredHits: desc("expired_hits_total", "Number of requests that found an expired item."),
+		loads:       desc("loads_total", "Number of backend calls."),
+		loadErrors:  desc("load_errors_total", "Number of backend calls that returned an error."),
+		evictions:   desc("evictions_total", "Number of items that have left the cache, per reason.", "reason"),
+		size:        desc("size", "Current number of items in the cache."),
+		weight:      desc("weight", "Current total weight of the items in the cache."),
+	}
+}
+
+// Prometheus collector
+type collector struct {
+	src StatsSource
+
+	hits, misses, expiredHits, loads, loadErrors, evictions, size, weight *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector interface.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.expiredHits
+	ch <- c.loads
+	ch <- c.loadErrors
+	ch <- c.evictions
+	ch <- c.size
+	ch <- c.weight
+}
+
+// Collect implements prometheus.Collector interface.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.src.Stats()
+
+	counter := func(desc *prometheus.Desc, value uint64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), labels...)
+	}
+
+	counter(c.hits, s.Hits)
+	counter(c.misses, s.Misses)
+	counter(c.expiredHits, s.ExpiredHits)
+	counter(c.loads, s.Loads)
+	counter(c.loadErrors, s.LoadErrors)
+	counter(c.evictions, s.EvictedCapacity, cache.EvictCapacity.String())
+	counter(c.evictions, s.EvictedExpired, cache.EvictExpired.String())
+	counter(c.evictions, s.EvictedDeleted, cache.EvictDeleted.String())
+	counter(c.evictions, s.EvictedReplaced, cache.EvictReplaced.String())
+	counter(c.evictions, s.EvictedClosed, cache.EvictClosed.String())
+
+	ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(s.Size))
+	ch <- prometheus.MustNewConstMetric(c.weight, prometheus.GaugeValue, float64(s.Weight))
+}
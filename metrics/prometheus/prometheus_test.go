@@ -0,0 +1,61 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxim2266/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	c := cache.New(2, time.Hour, func(key int) (int, error) { return -key, nil })
+
+	for _, k := range []int{1, 2, 1, 3} {
+		if _, err := c.Get(k); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+
+	if err := reg.Register(NewCollector(c, prometheus.Labels{"cache": "test"})); err != nil {
+		t.Error("error registering collector:", err)
+		return
+	}
+
+	const exp = `
+# HELP cache_evictions_total Number of items that have left the cache, per reason.
+# TYPE cache_evictions_total counter
+cache_evictions_total{cache="test",reason="capacity"} 1
+cache_evictions_total{cache="test",reason="closed"} 0
+cache_evictions_total{cache="test",reason="deleted"} 0
+cache_evictions_total{cache="test",reason="expired"} 0
+cache_evictions_total{cache="test",reason="replaced"} 0
+# HELP cache_hits_total Number of requests served from the cache.
+# TYPE cache_hits_total counter
+cache_hits_total{cache="test"} 1
+# HELP cache_misses_total Number of requests that required fetching data from the backend.
+# TYPE cache_misses_total counter
+cache_misses_total{cache="test"} 3
+# HELP cache_size Current number of items in the cache.
+# TYPE cache_size gauge
+cache_size{cache="test"} 2
+`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(exp),
+		"cache_evictions_total", "cache_hits_total", "cache_misses_total", "cache_size")
+
+	if err != nil {
+		t.Error("unexpected metrics:", err)
+		return
+	}
+
+	if n := testutil.CollectAndCount(NewCollector(c, nil)); n != 12 {
+		t.Errorf("unexpected number of metrics: %d instead of 12", n)
+		return
+	}
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Namespaced is an opaque type representing an LRU cache with keys of type "K" and values
+// of type "V", where the keys are scoped by namespace names, so that, for example, multiple
+// tenants can share one capacity budget, while their items can still be invalidated and
+// monitored separately. The options requiring functions of the key type (like WithOnEvict)
+// are not supported.
+type Namespaced[K comparable, V any] struct {
+	cache *LRU[scoped[K], V] // underlying cache
+
+	mu     sync.Mutex             // protects the namespace map
+	spaces map[string]*nsCounters // statistics of the namespaces with items in the cache, by name
+}
+
+// key within a namespace
+type scoped[K comparable] struct {
+	ns  string
+	key K
+}
+
+// per-namespace statistics counters
+type nsCounters struct {
+	requests   atomic.Uint64 // calls to Get
+	loads      atomic.Uint64 // backend calls
+	loadErrors atomic.Uint64 // backend errors
+
+	size int // number of items in the cache (protected by the namespace map lock)
+}
+
+// NewNamespaced creates a new namespaced LRU cache with keys of type "K" and values of type "V".
+// The backend function receives the namespace name along with the key.
+func NewNamespaced[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	backend func(string, K) (V, error),
+	opts ...Option,
+) *Namespaced[K, V] {
+	if backend == nil {
+		panic("attempt to create an LRU cache with nil backend function")
+	}
+
+	c := &Namespaced[K, V]{spaces: make(map[string]*nsCounters)}
+
+	c.cache = New(size, ttl, func(key scoped[K]) (value V, err error) {
+		stats := c.counters(key.ns)
+
+		stats.loads.Add(1)
+
+		if value, err = backend(key.ns, key.key); err != nil {
+			stats.loadErrors.Add(1)
+		}
+
+		return
+	}, opts...)
+
+	c.cache.onAdd = c.added
+	c.cache.onDrop = c.dropped
+	return c
+}
+
+// Namespace returns a view of the cache with all the keys scoped by the given namespace name.
+// The views are lightweight, and all the views of the same namespace are equivalent.
+func (c *Namespaced[K, V]) Namespace(name string) *Namespace[K, V] {
+	return &Namespace[K, V]{c: c, name: name}
+}
+
+// ClearNamespace evicts all the items of the given namespace, returning the number of items
+// evicted. The items are reported as deleted, and the statistics of the namespace get reset.
+// The method scans the whole cache, which takes O(n) time, with the cache locked.
+func (c *Namespaced[K, V]) ClearNamespace(name string) int {
+	return c.cache.removeIf(func(key scoped[K]) bool { return key.ns == name })
+}
+
+// Stats returns statistics of the whole cache.
+func (c *Namespaced[K, V]) Stats() Stats {
+	return c.cache.Stats()
+}
+
+// Close closes the cache (see LRU.Close), returning its error, if any.
+func (c *Namespaced[K, V]) Close() error {
+	return c.cache.Close()
+}
+
+// statistics counters of the given namespace, allocating new ones if necessary
+func (c *Namespaced[K, V]) counters(name string) *nsCounters {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.find(name)
+}
+
+// find the statistics counters of the given namespace, allocating new ones if necessary
+// (the namespace map must be locked)
+func (c *Namespaced[K, V]) find(name string) *nsCounters {
+	stats := c.spaces[name]
+
+	if stats == nil {
+		stats = new(nsCounters)
+		c.spaces[name] = stats
+	}
+
+	return stats
+}
+
+// count the item entering the cache (invoked with the cache locked)
+func (c *Namespaced[K, V]) added(key scoped[K]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.find(key.ns).size++
+}
+
+// count the item leaving the cache, forgetting the namespace without items (invoked with
+// the cache locked)
+func (c *Namespaced[K, V]) dropped(key scoped[K]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stats := c.spaces[key.ns]; stats != nil {
+		if stats.size--; stats.size <= 0 {
+			delete(c.spaces, key.ns)
+		}
+	}
+}
+
+// Namespace is a view of a namespaced cache, with all the keys scoped by the namespace name.
+type Namespace[K comparable, V any] struct {
+	c    *Namespaced[K, V]
+	name string
+}
+
+// NamespaceStats is a snapshot of namespace statistics.
+type NamespaceStats struct {
+	Requests   uint64 // number of requests to the namespace
+	Loads      uint64 // number of backend calls for the namespace
+	LoadErrors uint64 // number of backend calls that returned an error
+	Size       int    // current number of items of the namespace in the cache
+}
+
+// Name returns the name of the namespace.
+func (ns *Namespace[K, V]) Name() string {
+	return ns.name
+}
+
+// Get retrieves the value associated with the given key within the namespace, invoking
+// backend where necessary.
+func (ns *Namespace[K, V]) Get(key K) (V, error) {
+	ns.c.counters(ns.name).requests.Add(1)
+
+	return ns.c.cache.Get(scoped[K]{ns.name, key})
+}
+
+// Delete evicts the given key of the namespace from the cache.
+func (ns *Namespace[K, V]) Delete(key K) {
+	ns.c.cache.Delete(scoped[K]{ns.name, key})
+}
+
+// Clear evicts all the items of the namespace, returning the number of items evicted
+// (see Namespaced.ClearNamespace).
+func (ns *Namespace[K, V]) Clear() int {
+	return ns.c.ClearNamespace(ns.name)
+}
+
+// Stats returns statistics of the namespace. The statistics are kept while the namespace has
+// items in the cache, and get reset when its last item leaves the cache.
+func (ns *Namespace[K, V]) Stats() (s NamespaceStats) {
+	c := ns.c
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stats := c.spaces[ns.name]; stats != nil {
+		s = NamespaceStats{
+			Requests:   stats.requests.Load(),
+			Loads:      stats.loads.Load(),
+			LoadErrors: stats.loadErrors.Load(),
+			Size:       stats.size,
+		}
+	}
+
+	return
+}
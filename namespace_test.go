@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNamespaced(t *testing.T) {
+	c := NewNamespaced(10, time.Hour, func(ns string, key int) (string, error) {
+		if key < 0 {
+			return "", errors.New("invalid key")
+		}
+
+		return ns + "/" + strconv.Itoa(key), nil
+	})
+
+	a, b := c.Namespace("a"), c.Namespace("b")
+
+	for _, ns := range []*Namespace[int, string]{a, b, a} {
+		for key := -1; key < 3; key++ {
+			value, err := ns.Get(key)
+
+			switch {
+			case key < 0 && err == nil:
+				t.Error("missing error")
+				return
+			case key >= 0 && err != nil:
+				t.Error("unexpected error:", err)
+				return
+			case key >= 0 && value != ns.Name()+"/"+strconv.Itoa(key):
+				t.Errorf("unexpected value for %s/%d: %q", ns.Name(), key, value)
+				return
+			}
+		}
+	}
+
+	exp := NamespaceStats{Requests: 8, Loads: 4, LoadErrors: 1, Size: 4}
+
+	if s := a.Stats(); s != exp {
+		t.Errorf("unexpected stats of namespace a: %+v instead of %+v", s, exp)
+		return
+	}
+
+	if s := c.Stats(); s.Size != 8 || s.Misses != 8 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+
+	// clearing
+	if n := c.ClearNamespace("a"); n != 4 {
+		t.Errorf("unexpected number of items evicted: %d instead of 4", n)
+		return
+	}
+
+	// the statistics are reset along with the namespace
+	if s := c.Namespace("a").Stats(); s != (NamespaceStats{}) || len(c.spaces) != 1 {
+		t.Errorf("unexpected stats of namespace a: %+v", s)
+		return
+	}
+
+	b.Delete(1)
+
+	if s := b.Stats(); s.Size != 3 {
+		t.Errorf("unexpected size of namespace b: %d instead of 3", s.Size)
+		return
+	}
+
+	if n := b.Clear(); n != 3 {
+		t.Errorf("unexpected number of items evicted: %d instead of 3", n)
+		return
+	}
+
+	// no namespaces without items
+	for i := 0; i < 100; i++ {
+		if _, err := c.Namespace(strconv.Itoa(i)).Get(i); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	if s := c.Stats(); s.Size != 10 || len(c.spaces) != 10 {
+		t.Errorf("unexpected number of namespaces: %d for %d items", len(c.spaces), s.Size)
+		return
+	}
+
+	if err := c.Close(); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+}
@@ -0,0 +1,432 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Option is a function that configures optional behaviour of a cache.
+type Option func(*config)
+
+// Policy selects the algorithm used to choose a victim for eviction when the cache is full.
+type Policy int
+
+const (
+	// PolicyLRU evicts the least recently used item (default).
+	PolicyLRU Policy = iota
+
+	// PolicySLRU is the segmented LRU: new items are placed in a probation segment and only
+	// promoted to a protected segment on a second hit, so that one-shot keys get evicted
+	// before the items accessed more than once.
+	PolicySLRU
+
+	// PolicyClock is the CLOCK (second chance) approximation of LRU: a cache hit only sets
+	// a reference bit on the item instead of moving it to the top of the LRU list, so it
+	// does not need to lock the cache, unless WithExpireAfterAccess or WithRefreshAhead
+	// option is also given. On eviction, the items with the bit set are given a second chance.
+	PolicyClock
+
+	// PolicyMRU evicts the most recently used item, which suits the cyclic access patterns,
+	// like repeated scans over a set of keys larger than the cache, where LRU keeps evicting
+	// exactly the items to be requested next.
+	PolicyMRU
+
+	// PolicyGreedyDual is the GreedyDual-Size algorithm, which weighs the recency of each item
+	// against the cost of fetching it again, divided by its weight (see WithCost and WithWeigher),
+	// so that the cheap and large items get evicted before the expensive and small ones. Each item
+	// gets the priority of its cost plus the priority of the last evicted item, and the victim is
+	// the item with the lowest priority among a few least recently used ones.
+	PolicyGreedyDual
+)
+
+// WithPolicy sets the eviction policy of the cache.
+func WithPolicy(p Policy) Option {
+	return func(cfg *config) {
+		cfg.policy = p
+	}
+}
+
+// WithBufferedPromotion makes the cache record hits in small buffers instead of updating
+// the LRU list on every access, and then apply the recorded updates in batches, when a buffer
+// fills up, or when the cache gets locked anyway for an eviction, Put, or janitor run (see
+// WithJanitor). This way, a cache hit does not need to lock the cache (unless
+// WithExpireAfterAccess or WithRefreshAhead option is also given), at the cost of maintaining
+// only an approximate LRU order. The option has no effect under the CLOCK policy.
+func WithBufferedPromotion() Option {
+	return func(cfg *config) {
+		cfg.buffered = true
+	}
+}
+
+// WithNodePool makes the cache reuse its internal nodes instead of allocating a new one on
+// every cache miss, thus reducing the allocation rate at full capacity. The option has no
+// effect when cache hits are lock-free (see PolicyClock and WithBufferedPromotion).
+func WithNodePool() Option {
+	return func(cfg *config) {
+		cfg.pooled = true
+	}
+}
+
+// WithWeigher limits the total weight of all the items in the cache, where the weight of each
+// item is calculated by the given function once the value is fetched from the backend.
+// The least recently used items get evicted until the total weight is within the limit,
+// while the capacity of the cache still limits the number of items. Errors have zero weight.
+// The key and value types of the function must match those of the cache.
+func WithWeigher[K comparable, V any](maxWeight int, weigher func(K, V) int) Option {
+	return func(cfg *config) {
+		cfg.maxWeight, cfg.weigher = maxWeight, weigher
+	}
+}
+
+// WithMaxBytes limits the approximate total memory consumed by all the items in the cache.
+// The size of each item is estimated by traversing its key and value using reflection,
+// unless the key or value implements Sizer interface. The least recently used items get
+// evicted until the total size is within the limit. The option cannot be combined with
+// WithWeigher.
+func WithMaxBytes(n int) Option {
+	return func(cfg *config) {
+		cfg.maxBytes = n
+	}
+}
+
+// WithOnEvict sets a callback function to invoke whenever a successfully fetched value
+// leaves the cache, for the reason given. The callback is invoked without holding the lock
+// on the cache, from the goroutine that caused the eviction. The key and value types of the
+// function must match those of the cache.
+func WithOnEvict[K comparable, V any](fn func(K, V, EvictReason)) Option {
+	return func(cfg *config) {
+		cfg.onEvict = fn
+	}
+}
+
+// WithExpireAfterAccess sets the maximum time an item can stay in the cache without being
+// accessed. Every cache hit restarts the item's idle clock, while the TTL given to the
+// constructor still limits the total lifetime of the item.
+func WithExpireAfterAccess(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.accessTTL = d
+	}
+}
+
+// WithErrorTTL sets a separate time-to-live for the errors returned from the backend,
+// so that, for example, a transient failure does not stay in the cache for as long as
+// a valid value would.
+func WithErrorTTL(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.errorTTL = d
+	}
+}
+
+// WithNoErrorCaching disables caching of the errors returned from the backend: the error
+// is passed to all the callers waiting for the item, but the next request for the same key
+// invokes the backend again.
+func WithNoErrorCaching() Option {
+	return func(cfg *config) {
+		cfg.noErrors = true
+	}
+}
+
+// WithNotFoundTTL sets a separate time-to-live for the negative results, that is, ErrNotFound
+// returned from the backend, possibly wrapped, to report a missing key. Such results are then
+// cached for the given time, regardless of the options for caching errors (WithErrorTTL,
+// WithNoErrorCaching, WithErrorBackoff, and WithErrorPolicy).
+func WithNotFoundTTL(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.notFoundTTL = d
+	}
+}
+
+// WithErrorBackoff makes the cache keep each error returned from the backend for a time that
+// grows exponentially with the number of consecutive failures for the same key: the first
+// error is cached for the given initial duration, and each subsequent one for twice as long
+// as the previous, up to the given maximum, so that a failing key is retried promptly, but
+// without hammering the backend. A successful load resets the backoff for the key. The option
+// overrides the WithErrorTTL and WithNoErrorCaching options, while WithErrorPolicy overrides it.
+func WithErrorBackoff(initial, maxBackoff time.Duration) Option {
+	return func(cfg *config) {
+		cfg.errBackoff, cfg.errBackoffMax = initial, maxBackoff
+	}
+}
+
+// WithErrorPolicy sets a function that selects time-to-live for each error returned from
+// the backend, where a non-positive duration means the error is not to be cached at all.
+// The policy overrides the WithErrorTTL and WithNoErrorCaching options.
+func WithErrorPolicy(policy func(error) time.Duration) Option {
+	return func(cfg *config) {
+		cfg.errorPolicy = policy
+	}
+}
+
+// WithLoadRetry makes the cache retry a failed backend call up to the given total number
+// of attempts before the error gets cached. The delay between the attempts starts from the
+// given backoff value and doubles after each failed attempt. A panic in the backend is never
+// retried.
+func WithLoadRetry(attempts int, backoff time.Duration) Option {
+	return func(cfg *config) {
+		cfg.attempts, cfg.backoff = attempts, backoff
+	}
+}
+
+// WithPanicRetry makes the cache return a *PanicError from a load where the backend function
+// has panicked, instead of propagating the panic, and forget the load, so that the next request
+// for the key invokes the backend again. By default, the panic is propagated to the caller that
+// has started the load, while the other callers waiting for the key, and all the later ones
+// until the item expires, receive a *PanicError.
+func WithPanicRetry() Option {
+	return func(cfg *config) {
+		cfg.panicRetry = true
+	}
+}
+
+// WithLoadRateLimit limits the rate of backend calls across all keys. When the limit is
+// exceeded, the caller either waits for the limiter (if "wait" is true), or receives
+// ErrRateLimited error, which is not cached.
+func WithLoadRateLimit(limiter RateLimiter, wait bool) Option {
+	return func(cfg *config) {
+		cfg.limiter, cfg.waitLimiter = limiter, wait
+	}
+}
+
+// WithMaxConcurrentLoads limits the number of backend calls in progress at any time, across
+// all keys. The requests that need to call the backend when the limit is reached wait until
+// one of the calls in progress completes.
+func WithMaxConcurrentLoads(n int) Option {
+	return func(cfg *config) {
+		cfg.maxLoads = n
+	}
+}
+
+// WithLoadShedding limits the number of loads in progress at any time, across all keys,
+// including their retries, where a request that needs to load an item when the limit is
+// reached fails immediately with ErrOverloaded, which is not cached, instead of queueing up
+// in front of the backend. The expired items can still be served stale while overloaded
+// (see WithStaleWhileRevalidate), and the fallback loaders, if any, are still tried (see
+// WithFallback). The number of loads shed is reported in Stats.Shed.
+func WithLoadShedding(n int) Option {
+	return func(cfg *config) {
+		cfg.shedding = n
+	}
+}
+
+// WithTTLJitter randomises the TTL of each item within ±fraction of its nominal value
+// (e.g., 0.1 for ±10%), so that the items loaded at the same time do not all expire at
+// the same moment. The fraction must be within [0, 1) interval.
+func WithTTLJitter(fraction float64) Option {
+	return func(cfg *config) {
+		cfg.jitter = fraction
+	}
+}
+
+// WithJanitor starts a background goroutine that removes expired items from the cache
+// every given interval, so that the memory is reclaimed even for the keys that are never
+// requested again. The goroutine is stopped by the Close method of the cache.
+func WithJanitor(interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.janitor = interval
+	}
+}
+
+// WithStaleWhileRevalidate enables serving of expired values: a request for an item that
+// has expired less than maxStale ago immediately returns the stale value and triggers
+// an asynchronous reload of the item from the backend. Cached errors are never served stale.
+func WithStaleWhileRevalidate(maxStale time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxStale = maxStale
+	}
+}
+
+// WithRefreshAhead enables asynchronous reloading of the items nearing their expiration:
+// a cache hit on an item older than the given fraction of its TTL (e.g., 0.8) triggers
+// a background reload of the item, while the current value is returned immediately.
+// The fraction must be within [0, 1) interval, where 0 disables the feature.
+func WithRefreshAhead(fraction float64) Option {
+	return func(cfg *config) {
+		cfg.ahead = fraction
+	}
+}
+
+// WithEvents enables the stream of cache lifecycle events (see the Events method), buffering
+// up to the given number of events.
+func WithEvents(capacity int) Option {
+	return func(cfg *config) {
+		cfg.events = capacity
+	}
+}
+
+// WithInvalidationPublisher sets a function to invoke with the key on every local deletion
+// from the cache, for broadcasting the invalidation to the other replicas of the cache, where
+// it is to be passed to the ApplyInvalidation method. The function is invoked without holding
+// the lock on the cache. The key type of the function must match that of the cache.
+func WithInvalidationPublisher[K comparable](fn func(K)) Option {
+	return func(cfg *config) {
+		cfg.publish = fn
+	}
+}
+
+// Loader is a backend function in its most general form, returning the value for the given key
+// with its time-to-live, where a non-positive TTL means the default TTL of the cache.
+type Loader[K comparable, V any] func(key K) (V, time.Duration, error)
+
+// WithLoaderMiddleware adds a decorator for the backend function of the cache, for layering
+// metrics, tracing, authentication, etc. The decorators are applied in the order of the options,
+// so that the first one is the outermost. The decorators also apply to the loader functions
+// passed to GetWith method. The key and value types of the function must match those of the cache.
+func WithLoaderMiddleware[K comparable, V any](mw func(next Loader[K, V]) Loader[K, V]) Option {
+	return func(cfg *config) {
+		cfg.middleware = append(cfg.middleware, mw)
+	}
+}
+
+// WithClock sets the function the cache uses to obtain the current time, instead of time.Now,
+// which allows for testing the TTL-dependent logic without waiting for the real time to pass.
+// The function must be safe for concurrent use. Background activities, like the janitor
+// or load retries, are still scheduled in real time.
+func WithClock(now func() time.Time) Option {
+	return func(cfg *config) {
+		cfg.clock = now
+	}
+}
+
+// WithCoarseClock makes the cache read the current time from a clock updated in the background
+// with the given resolution, instead of calling time.Now on every operation, which reduces
+// the cost of cache hits at high request rates. All the time the cache measures, including
+// the TTLs and the load durations (see Info), is then only accurate to within the resolution,
+// so the option is for the caches with TTLs much longer than the resolution, like seconds
+// or more. The option cannot be combined with WithClock.
+func WithCoarseClock(resolution time.Duration) Option {
+	return func(cfg *config) {
+		cfg.tick = resolution
+	}
+}
+
+// WithWriter sets a function for writing values to the backend store through the cache
+// (see the Put method). The key and value types of the function must match those of the cache.
+func WithWriter[K comparable, V any](fn func(context.Context, K, V) error) Option {
+	return func(cfg *config) {
+		cfg.writer = fn
+	}
+}
+
+// WithWriteBack switches the writer of the cache (see WithWriter) to the write-behind mode,
+// where the Put method only updates the cache and queues the write, while the queued writes are
+// flushed to the backend in the background at the given interval, with only the latest value
+// written for each key. The queue can also be flushed explicitly (see the Flush method), and
+// it gets flushed on closing the cache. Until a value is written, cache misses on its key
+// return the queued value instead of invoking the backend.
+func WithWriteBack(interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.writeBack = interval
+	}
+}
+
+// WithUnbounded removes the capacity limit of the cache, so that the items only get removed
+// when they expire (see also WithJanitor), or get deleted, or to keep the total weight within
+// the limit, if any. The capacity given to the constructor is then only a hint for the initial
+// allocation. The memory usage of such a cache is not bounded by anything but the set of keys,
+// so the option is to be used with care. The option cannot be combined with
+// WithAdaptiveCapacity.
+func WithUnbounded() Option {
+	return func(cfg *config) {
+		cfg.unbounded = true
+	}
+}
+
+// WithAdaptiveCapacity starts a background goroutine that adjusts the capacity of the cache every
+// given interval, within [minSize, maxSize] bounds, to hold the hit ratio observed over the interval
+// near the given target: the capacity grows by 10% while the hit ratio is below the target, and
+// shrinks by 5% while it is above, reclaiming the memory of an over-provisioned cache. The capacity
+// given to the constructor is the initial one, and must be within the bounds. The goroutine is
+// stopped by the Close method of the cache.
+func WithAdaptiveCapacity(minSize, maxSize int, target float64, interval time.Duration) Option {
+	return func(cfg *config) {
+		cfg.adaptive = adaptiveConfig{minSize, maxSize, target, interval}
+	}
+}
+
+// WithHitRatioWindow enables tracking of the hit ratio over the given rolling window of time
+// (see Stats.RecentHitRatio), with the resolution of 1/10 of the window. The tracking is done by
+// a background goroutine, which is stopped by the Close method of the cache.
+func WithHitRatioWindow(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.window = d
+	}
+}
+
+// cache configuration
+type config struct {
+	policy    Policy        // eviction policy
+	buffered  bool          // buffered promotion
+	pooled    bool          // node pooling
+	accessTTL time.Duration // max. idle time for each item (0 if unlimited)
+	errorTTL  time.Duration // time-to-live for errors (0 if same as for values)
+	noErrors  bool          // do not cache errors
+	jitter    float64       // TTL jitter fraction
+	janitor   time.Duration // janitor interval (0 if disabled)
+	maxStale  time.Duration // max. time to serve stale values (0 if disabled)
+	ahead     float64       // refresh-ahead fraction of TTL (0 if disabled)
+	attempts  int           // max. number of backend calls per load (0 if 1)
+	backoff   time.Duration // initial delay between load attempts
+	events    int           // capacity of the event channel (0 if disabled)
+	maxLoads  int           // max. number of concurrent backend calls (0 if unlimited)
+	shedding  int           // max. number of concurrent loads before shedding (0 if disabled)
+	writeBack time.Duration // write-back flush interval (0 if write-through)
+	window    time.Duration // hit ratio window (0 if disabled)
+	hotKeys   int           // number of hot keys to track (0 if disabled)
+	door      int           // doorkeeper window in distinct keys (0 if disabled)
+	sketch    int           // frequency sketch size in distinct keys (0 if disabled)
+	unbounded bool          // no capacity limit
+	tick      time.Duration // coarse clock resolution (0 if disabled)
+
+	errBackoff    time.Duration // initial error TTL with backoff (0 if disabled)
+	errBackoffMax time.Duration // max. error TTL with backoff
+	notFoundTTL   time.Duration // time-to-live for negative results (0 if same as for errors)
+	panicRetry    bool          // retry loads after backend panics
+	latencyCost   bool          // load latency as the cost of each item
+
+	adaptive adaptiveConfig // adaptive capacity parameters
+
+	minTTL time.Duration // min. adaptive TTL
+	maxTTL time.Duration // max. adaptive TTL (0 if disabled)
+
+	health func(context.Context) error // backend health check (nil if disabled)
+	probe  time.Duration               // health check interval
+
+	workers workerConfig // worker pool parameters
+
+	prefixIndex bool // maintain the prefix tree of the keys
+
+	orderedIndex any // ordered index constructor (func() keyIndex[K])
+
+	errorPolicy func(error) time.Duration // error TTL selector
+	limiter     RateLimiter               // backend rate limiter
+	waitLimiter bool                      // wait for the rate limiter instead of failing
+	weigher     any                       // weigher function (func(K, V) int)
+	cost        any                       // cost function (func(K, V) float64)
+	maxWeight   int                       // max. total weight
+	maxBytes    int                       // max. total size in bytes
+	onEvict     any                       // eviction callback (func(K, V, EvictReason))
+	publish     any                       // invalidation publisher (func(K))
+	tagger      any                       // tagger function (func(K, V) []string)
+	priority    any                       // priority function (func(K, V) Priority)
+	keyString   any                       // key to string conversion (func(K) string)
+	writer      any                       // backend writer (func(context.Context, K, V) error)
+	middleware  []any                     // loader decorators (func(Loader[K, V]) Loader[K, V])
+	fallbacks   []any                     // fallback loaders (Loader[K, V])
+	clock       func() time.Time          // current time source (nil for time.Now)
+}
+
+// build configuration from the given options
+func makeConfig(opts []Option) (cfg config) {
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return
+}
+
+// adaptive capacity parameters
+type adaptiveConfig struct {
+	min, max int           // capacity bounds
+	target   float64       // target hit ratio
+	interval time.Duration // adjustment interval (0 if disabled)
+}
@@ -0,0 +1,209 @@
+// Package otelcache provides OpenTelemetry instrumentation for caches.
+package otelcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/maxim2266/cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation scope name
+const scope = "github.com/maxim2266/cache/otelcache"
+
+// StatsSource is the interface to any cache that provides statistics.
+type StatsSource interface {
+	Stats() cache.Stats
+}
+
+// Option configures the instrumentation.
+type Option func(*config)
+
+// WithMeterProvider sets the meter provider to use instead of the global one.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(cfg *config) { cfg.mp = mp }
+}
+
+// WithTracerProvider sets the tracer provider to use instead of the global one.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(cfg *config) { cfg.tp = tp }
+}
+
+type config struct {
+	mp metric.MeterProvider
+	tp trace.TracerProvider
+}
+
+func makeConfig(opts []Option) (cfg config) {
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.mp == nil {
+		cfg.mp = otel.GetMeterProvider()
+	}
+
+	if cfg.tp == nil {
+		cfg.tp = otel.GetTracerProvider()
+	}
+
+	return
+}
+
+// ObserveStats registers asynchronous instruments reporting statistics of the given cache.
+// Every observation carries the "cache.name" attribute set to the given name. The returned
+// registration should be unregistered when the cache is closed.
+func ObserveStats(name string, src StatsSource, opts ...Option) (metric.Registration, error) {
+	cfg := makeConfig(opts)
+	meter := cfg.mp.Meter(scope)
+
+	hits, err := meter.Int64ObservableCounter("cache.hits",
+		metric.WithDescription("Number of requests served from the cache."))
+
+	if err != nil {
+		return nil, err
+	}
+
+	misses, err := meter.Int64ObservableCounter("cache.misses",
+		metric.WithDescription("Number of requests that required fetching data from the backend."))
+
+	if err != nil {
+		return nil, err
+	}
+
+	loads, err := meter.Int64ObservableCounter("cache.loads",
+		metric.WithDescription("Number of backend calls."))
+
+	if err != nil {
+		return nil, err
+	}
+
+	loadErrors, err := meter.Int64ObservableCounter("cache.load.errors",
+		metric.WithDescription("Number of backend calls that returned an error."))
+
+	if err != nil {
+		return nil, err
+	}
+
+	evictions, err := meter.Int64ObservableCounter("cache.evictions",
+		metric.WithDescription("Number of items that have left the cache, per reason."))
+
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := meter.Int64ObservableGauge("cache.size",
+		metric.WithDescription("Current number of items in the cache."))
+
+	if err != nil {
+		return nil, err
+	}
+
+	cacheName := metric.WithAttributes(attribute.String("cache.name", name))
+
+	reason := func(r cache.EvictReason) metric.ObserveOption {
+		return metric.WithAttributes(attribute.String("cache.name", name), attribute.String("reason", r.String()))
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := src.Stats()
+
+		o.ObserveInt64(hits, int64(s.Hits), cacheName)
+		o.ObserveInt64(misses, int64(s.Misses), cacheName)
+		o.ObserveInt64(loads, int64(s.Loads), cacheName)
+		o.ObserveInt64(loadErrors, int64(s.LoadErrors), cacheName)
+		o.ObserveInt64(evictions, int64(s.EvictedCapacity), reason(cache.EvictCapacity))
+		o.ObserveInt64(evictions, int64(s.EvictedExpired), reason(cache.EvictExpired))
+		o.ObserveInt64(evictions, int64(s.EvictedDeleted), reason(cache.EvictDeleted))
+		o.ObserveInt64(evictions, int64(s.EvictedReplaced), reason(cache.EvictReplaced))
+		o.ObserveInt64(evictions, int64(s.EvictedClosed), reason(cache.EvictClosed))
+		o.ObserveInt64(size, int64(s.Size), cacheName)
+		return nil
+	}, hits, misses, loads, loadErrors, evictions, size)
+}
+
+// Backend wraps the given backend function so that every call is recorded as a span named
+// after the cache, and its duration is recorded in the "cache.load.duration" histogram.
+// The backend functions accepted by the cache take no context, so each span starts
+// a new trace; see ContextBackend for linking loads to the caller's trace.
+func Backend[K comparable, V any](name string, backend func(K) (V, error), opts ...Option) func(K) (V, error) {
+	fn := wrap(name, func(_ context.Context, key K) (value V, _ time.Duration, err error) {
+		value, err = backend(key)
+		return
+	}, opts)
+
+	return func(key K) (V, error) {
+		value, _, err := fn(context.Background(), key)
+		return value, err
+	}
+}
+
+// BackendWithTTL is the same as Backend, but for the backend functions used with cache.NewWithTTL.
+func BackendWithTTL[K comparable, V any](name string, backend func(K) (V, time.Duration, error), opts ...Option) func(K) (V, time.Duration, error) {
+	fn := wrap(name, func(_ context.Context, key K) (V, time.Duration, error) {
+		return backend(key)
+	}, opts)
+
+	return func(key K) (V, time.Duration, error) {
+		return fn(context.Background(), key)
+	}
+}
+
+// ContextBackend wraps the given context-aware backend function so that every call is recorded
+// as a child span of the supplied context, named after the cache, and its duration is recorded
+// in the "cache.load.duration" histogram. The result is meant to be called from a backend
+// closure that has access to a long-lived context carrying the parent span.
+func ContextBackend[K comparable, V any](name string, backend func(context.Context, K) (V, error), opts ...Option) func(context.Context, K) (V, error) {
+	fn := wrap(name, func(ctx context.Context, key K) (value V, _ time.Duration, err error) {
+		value, err = backend(ctx, key)
+		return
+	}, opts)
+
+	return func(ctx context.Context, key K) (V, error) {
+		value, _, err := fn(ctx, key)
+		return value, err
+	}
+}
+
+// instrumentation wrapper
+func wrap[K comparable, V any](name string, backend func(context.Context, K) (V, time.Duration, error), opts []Option) func(context.Context, K) (V, time.Duration, error) {
+	cfg := makeConfig(opts)
+	tracer := cfg.tp.Tracer(scope)
+
+	// the API guarantees a usable instrument even on error
+	duration, err := cfg.mp.Meter(scope).Float64Histogram("cache.load.duration",
+		metric.WithDescription("Duration of backend calls."),
+		metric.WithUnit("s"))
+
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	cacheName := attribute.String("cache.name", name)
+
+	return func(ctx context.Context, key K) (value V, ttl time.Duration, err error) {
+		ctx, span := tracer.Start(ctx, name, trace.WithAttributes(cacheName))
+
+		defer span.End()
+
+		start := time.Now()
+		value, ttl, err = backend(ctx, key)
+
+		outcome := attribute.String("outcome", "ok")
+
+		if err != nil {
+			outcome = attribute.String("outcome", "error")
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(cacheName, outcome))
+		return
+	}
+}
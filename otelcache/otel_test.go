@@ -0,0 +1,191 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxim2266/cache"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestObserveStats(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	c := cache.New(10, time.Hour, func(key int) (int, error) { return -key, nil })
+
+	defer c.Close()
+
+	reg, err := ObserveStats("test", c, WithMeterProvider(mp))
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	defer reg.Unregister()
+
+	for _, k := range []int{1, 2, 1} {
+		if _, err := c.Get(k); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	values, err := collect(reader)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	exp := map[string]int64{
+		"cache.hits":        1,
+		"cache.misses":      2,
+		"cache.loads":       2,
+		"cache.load.errors": 0,
+		"cache.evictions":   0,
+		"cache.size":        2,
+	}
+
+	for name, value := range exp {
+		if v, ok := values[name]; !ok || v != value {
+			t.Errorf("metric %q: unexpected value %d instead of %d", name, v, value)
+			return
+		}
+	}
+}
+
+func TestBackend(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	errOdd := errors.New("odd key")
+
+	backend := Backend("test", func(key int) (int, error) {
+		if key&1 != 0 {
+			return 0, errOdd
+		}
+
+		return -key, nil
+	}, WithMeterProvider(mp), WithTracerProvider(tp))
+
+	c := cache.New(10, time.Hour, backend)
+
+	defer c.Close()
+
+	if v, err := c.Get(2); err != nil || v != -2 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	if _, err := c.Get(3); !errors.Is(err, errOdd) {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	spans := rec.Ended()
+
+	if len(spans) != 2 {
+		t.Errorf("unexpected number of spans: %d instead of 2", len(spans))
+		return
+	}
+
+	for _, span := range spans {
+		if span.Name() != "test" {
+			t.Errorf("unexpected span name: %q", span.Name())
+			return
+		}
+	}
+
+	if spans[0].Status().Code == codes.Error || spans[1].Status().Code != codes.Error {
+		t.Error("unexpected span status")
+		return
+	}
+
+	values, err := collect(reader)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if values["cache.load.duration"] != 2 {
+		t.Errorf("unexpected number of recorded durations: %d instead of 2", values["cache.load.duration"])
+		return
+	}
+}
+
+func TestContextBackend(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	load := ContextBackend("test", func(_ context.Context, key int) (int, error) {
+		return -key, nil
+	}, WithTracerProvider(tp))
+
+	c := cache.New(10, time.Hour, func(key int) (int, error) { return load(ctx, key) })
+
+	defer c.Close()
+
+	if v, err := c.Get(1); err != nil || v != -1 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	parent.End()
+
+	spans := rec.Ended()
+
+	if len(spans) != 2 {
+		t.Errorf("unexpected number of spans: %d instead of 2", len(spans))
+		return
+	}
+
+	if spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("the load span is not a child of the parent span")
+		return
+	}
+}
+
+// sums of all data points per metric; histograms are represented by their counts
+func collect(reader sdkmetric.Reader) (map[string]int64, error) {
+	var rm metricdata.ResourceMetrics
+
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int64)
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					values[m.Name] += dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					values[m.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					values[m.Name] += int64(dp.Count)
+				}
+			}
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,213 @@
+// Package peer implements a cache distributed over a fleet of processes, where each key
+// is owned by one process (peer), selected by consistent hashing, and only the owner
+// loads the key from the origin backend, while the other peers fetch it from the owner.
+package peer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/maxim2266/cache"
+	"github.com/maxim2266/cache/internal/ring"
+)
+
+// max. size of a peer response
+const maxResponse = 64 * 1024 * 1024
+
+// Group is an opaque type representing the local part of a distributed cache with keys
+// of type "K" and values of type "V".
+type Group[K comparable, V any] struct {
+	cache  *cache.LRU[K, V]   // local cache
+	origin func(K) (V, error) // origin backend
+	codec  cache.Codec[K, V]  // codec for keys and values
+	client *http.Client       // HTTP client for peer requests
+	self   int                // index of this process in the peer list
+	peers  []string           // URLs of the peers
+	ring   *ring.Ring         // consistent hashing of keys to peers
+}
+
+// NewGroup creates a new distributed cache, where "self" is the URL of this process and
+// "peers" is the list of URLs of all processes in the fleet (including "self"), each pointing
+// to the Group handler mounted on the corresponding peer. On a cache miss, the key is fetched
+// from its owning peer, or from the origin backend if this process is the owner. If the owner
+// cannot be reached, the key is loaded from the origin locally. Keys and values are passed
+// between the peers encoded by the given codec. The size, TTL and options apply to the local
+// cache, which stores the values from both the origin and the peers.
+func NewGroup[K comparable, V any](
+	self string,
+	peers []string,
+	codec cache.Codec[K, V],
+	size int,
+	ttl time.Duration,
+	origin func(K) (V, error),
+	opts ...cache.Option,
+) *Group[K, V] {
+	i := slices.Index(peers, self)
+
+	if i < 0 {
+		panic("attempt to create a peer group without self in the list of peers")
+	}
+
+	if codec == nil {
+		panic("attempt to create a peer group with nil codec")
+	}
+
+	if origin == nil {
+		panic("attempt to create a peer group with nil backend function")
+	}
+
+	g := &Group[K, V]{
+		origin: origin,
+		codec:  codec,
+		client: http.DefaultClient,
+		self:   i,
+		peers:  slices.Clone(peers),
+		ring:   ring.New(peers...),
+	}
+
+	g.cache = cache.New(size, ttl, g.fetch, opts...)
+	return g
+}
+
+// SetClient sets the HTTP client for requests to the peers, instead of http.DefaultClient.
+// The method must be called before the group is used.
+func (g *Group[K, V]) SetClient(client *http.Client) {
+	g.client = client
+}
+
+// Get retrieves the value associated with the given key from the local cache, the owning
+// peer, or the origin backend.
+func (g *Group[K, V]) Get(key K) (V, error) {
+	return g.cache.Get(key)
+}
+
+// Delete evicts the given key from the local cache only.
+func (g *Group[K, V]) Delete(key K) {
+	g.cache.Delete(key)
+}
+
+// Stats returns statistics of the local cache.
+func (g *Group[K, V]) Stats() cache.Stats {
+	return g.cache.Stats()
+}
+
+// Close closes the local cache (see LRU.Close), returning its error, if any.
+func (g *Group[K, V]) Close() error {
+	return g.cache.Close()
+}
+
+// ServeHTTP implements http.Handler interface, serving the requests from the other peers.
+func (g *Group[K, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxResponse))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var e cache.Entry[K, V]
+
+	if err = g.codec.Decode(data, &e); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// a key this process does not own (e.g., while the peer lists disagree) is loaded
+	// directly from the origin, to avoid forwarding it back and forth
+	if g.owner(e.Key) == g.self {
+		e.Value, err = g.cache.Get(e.Key)
+	} else {
+		e.Value, err = g.origin(e.Key)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if data, err = g.codec.Encode(&e); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
+
+// backend function for the local cache
+func (g *Group[K, V]) fetch(key K) (V, error) {
+	if i := g.owner(key); i != g.self {
+		value, err := g.fetchFrom(g.peers[i], key)
+
+		if _, ok := err.(*Error); ok || err == nil {
+			return value, err
+		}
+	}
+
+	return g.origin(key)
+}
+
+// fetch the key from the given peer
+func (g *Group[K, V]) fetchFrom(peer string, key K) (value V, err error) {
+	e := cache.Entry[K, V]{Key: key}
+
+	data, err := g.codec.Encode(&e)
+
+	if err != nil {
+		return
+	}
+
+	resp, err := g.client.Post(peer, "application/octet-stream", bytes.NewReader(data))
+
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if data, err = io.ReadAll(io.LimitReader(resp.Body, maxResponse)); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err = g.codec.Decode(data, &e); err == nil {
+			value = e.Value
+		}
+
+	case http.StatusBadGateway:
+		err = &Error{Peer: peer, Msg: strings.TrimSpace(string(data))}
+
+	default:
+		err = errors.New("unexpected HTTP status from peer " + peer + ": " + resp.Status)
+	}
+
+	return
+}
+
+// index of the peer owning the given key
+func (g *Group[K, V]) owner(key K) int {
+	return g.ring.Pick(fmt.Sprint(key))
+}
+
+// Error is the error returned from the origin backend on a remote peer.
+type Error struct {
+	Peer string // peer URL
+	Msg  string // error message
+}
+
+// Error implements error interface.
+func (e *Error) Error() string {
+	return "peer " + e.Peer + ": " + e.Msg
+}
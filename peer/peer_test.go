@@ -0,0 +1,135 @@
+package peer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxim2266/cache"
+)
+
+func TestGroup(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		loads = make(map[int]int)
+	)
+
+	origin := func(key int) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		loads[key]++
+
+		if key < 0 {
+			return "", errors.New("invalid key")
+		}
+
+		return strconv.Itoa(key), nil
+	}
+
+	groups := startGroups(t, 3, origin)
+
+	// each key is loaded from the origin only once across the fleet
+	for _, g := range groups {
+		for key := range 30 {
+			if v, err := g.Get(key); err != nil || v != strconv.Itoa(key) {
+				t.Error("unexpected result:", v, err)
+				return
+			}
+		}
+	}
+
+	for key := range 30 {
+		if n := loads[key]; n != 1 {
+			t.Errorf("key %d: unexpected number of loads: %d instead of 1", key, n)
+			return
+		}
+	}
+
+	// origin errors are passed to the other peers
+	var owner int
+
+	for i, g := range groups {
+		if g.owner(-1) == g.self {
+			owner = i
+		}
+	}
+
+	for i, g := range groups {
+		_, err := g.Get(-1)
+
+		if err == nil {
+			t.Error("missing error")
+			return
+		}
+
+		var perr *Error
+
+		if isPeer := errors.As(err, &perr); isPeer == (i == owner) {
+			t.Errorf("group %d: unexpected error type: %v", i, err)
+			return
+		}
+	}
+
+	if n := loads[-1]; n != 1 {
+		t.Errorf("unexpected number of loads: %d instead of 1", n)
+		return
+	}
+}
+
+func TestGroupPeerDown(t *testing.T) {
+	var calls int
+
+	origin := func(key int) (int, error) {
+		calls++
+		return -key, nil
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	dead := srv.URL
+
+	srv.Close()
+
+	g := NewGroup("self", []string{"self", dead}, cache.JSONCodec[int, int]{}, 100, time.Hour, origin)
+
+	defer g.Close()
+
+	for key := range 20 {
+		if v, err := g.Get(key); err != nil || v != -key {
+			t.Error("unexpected result:", v, err)
+			return
+		}
+	}
+
+	if calls != 20 {
+		t.Errorf("unexpected number of origin calls: %d instead of 20", calls)
+		return
+	}
+}
+
+// start a fleet of groups, each on its own HTTP server
+func startGroups(t *testing.T, n int, origin func(int) (string, error)) []*Group[int, string] {
+	groups := make([]*Group[int, string], n)
+	servers := make([]*httptest.Server, n)
+	urls := make([]string, n)
+
+	for i := range n {
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			groups[i].ServeHTTP(w, r)
+		}))
+
+		t.Cleanup(servers[i].Close)
+
+		urls[i] = servers[i].URL
+	}
+
+	for i := range n {
+		groups[i] = NewGroup(urls[i], urls, cache.JSONCodec[int, string]{}, 100, time.Hour, origin)
+	}
+
+	return groups
+}
@@ -0,0 +1,61 @@
+package cache
+
+// Pin exempts the given key from capacity eviction, either immediately, if the key is in the cache,
+// or as soon as it gets there. Pinned items still expire, and can be deleted explicitly, but remain
+// pinned until Unpin is called for the key. Pinned items count towards the capacity of the cache,
+// and when the cache is full of pinned items, it grows beyond its capacity.
+func (c *LRU[K, V]) Pin(key K) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return
+	}
+
+	if c.pins == nil {
+		c.pins = make(map[K]bool)
+	}
+
+	c.pins[key] = true
+
+	if node := c.nodes[key]; node != nil && !node.pinned {
+		node.remove()
+
+		if node.protected {
+			node.protected = false
+			c.numProt--
+		}
+
+		node.addTo(&c.pinned)
+		node.pinned = true
+	}
+}
+
+// Unpin makes the given key subject to capacity eviction again. If the cache is over its capacity,
+// the least recently used items get evicted.
+func (c *LRU[K, V]) Unpin(key K) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	delete(c.pins, key)
+
+	if node := c.nodes[key]; node != nil && node.pinned {
+		node.remove()
+		node.addTo(c.home(node))
+		node.pinned = false
+
+		for len(c.nodes) > c.size && c.evict() {
+		}
+
+		c.trim()
+	}
+}
+
+// add the new node to the appropriate list as the most recent
+func (c *LRU[K, V]) link(node *lruNode[K, V]) {
+	if node.pinned = c.pins[node.key]; node.pinned {
+		node.addTo(&c.pinned)
+	} else {
+		node.addTo(c.home(node))
+	}
+}
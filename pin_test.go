@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPin(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(3, time.Hour, backend.fn)
+
+	// pinning before and after loading
+	c.Pin(1)
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.Pin(2)
+
+	if err := fill(c.Get, []int{3, 4, 5, 1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 3, 4, 5}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if keys := fmt.Sprint(listKeys(&c.pinned), listKeys(&c.list)); keys != "[1 2] [5]" {
+		t.Error("unexpected keys:", keys)
+		return
+	}
+
+	// the cache grows beyond its capacity when full of pinned items
+	c.Pin(6)
+	c.Pin(7)
+
+	if err := fill(c.Get, []int{6, 7}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if len(c.nodes) != 4 {
+		t.Errorf("unexpected cache size: %d instead of 4", len(c.nodes))
+		return
+	}
+
+	// unpinning shrinks the cache back
+	c.Unpin(6)
+
+	if keys := fmt.Sprint(listKeys(&c.pinned), listKeys(&c.list)); keys != "[1 2 7] []" {
+		t.Error("unexpected keys:", keys)
+		return
+	}
+
+	// pinned items can be deleted
+	c.Delete(1)
+
+	if c.nodes[1] != nil || len(c.nodes) != 2 {
+		t.Error("the pinned item has not been deleted")
+		return
+	}
+}
@@ -0,0 +1,32 @@
+package cache
+
+// With node pooling, each node counts the references held by the callers, and gets recycled
+// when it has left the cache and the last reference is released.
+const nodeRetired = 1 << 30 // flag added to the reference count when the node leaves the cache
+
+// acquire a reference to the node (the cache must be locked)
+func (c *LRU[K, V]) acquire(node *lruNode[K, V]) {
+	if c.pooled {
+		node.refs.Add(1)
+	}
+}
+
+// release a reference to the node
+func (c *LRU[K, V]) release(node *lruNode[K, V]) {
+	if c.pooled && node.refs.Add(-1) == nodeRetired {
+		c.recycle(node)
+	}
+}
+
+// mark the node as removed from the cache (the cache must be locked)
+func (c *LRU[K, V]) retire(node *lruNode[K, V]) {
+	if c.pooled && node.refs.Add(nodeRetired) == nodeRetired {
+		c.recycle(node)
+	}
+}
+
+// reset the node and return it to the pool
+func (c *LRU[K, V]) recycle(node *lruNode[K, V]) {
+	*node = lruNode[K, V]{}
+	c.pool.Put(node)
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNodePool(t *testing.T) {
+	var (
+		backend intBackendMT
+		wg      sync.WaitGroup
+	)
+
+	c := New(10, time.Hour, backend.fn, WithNodePool(), WithRefreshAhead(0.5))
+
+	if !c.pooled {
+		t.Error("node pooling is not enabled")
+		return
+	}
+
+	wg.Add(4)
+
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20000; j++ {
+				k := rand.Intn(100)
+
+				if rand.Intn(100) == 0 {
+					c.Delete(k)
+				} else if err := getOne(c, k); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// steady-state churn
+	i := 0
+
+	allocs := testing.AllocsPerRun(10000, func() {
+		getOne(c, i%100)
+		i++
+	})
+
+	if allocs > 0.1 {
+		t.Errorf("too many allocations per cache miss: %.2f", allocs)
+		return
+	}
+}
+
+func BenchmarkChurn(b *testing.B) {
+	benchChurn(b)
+}
+
+func BenchmarkChurnPooled(b *testing.B) {
+	benchChurn(b, WithNodePool())
+}
+
+func benchChurn(b *testing.B, opts ...Option) {
+	c := New(50, time.Hour, simpleBackend, opts...)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := getOne(c, i%100); err != nil {
+			b.Error(err)
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cache
+
+import "strings"
+
+// WithKeyString sets the function converting the keys of the cache to strings for DeletePrefix,
+// for the caches with keys of types other than string. The function may map different keys to
+// the same string. The key type of the function must match that of the cache.
+func WithKeyString[K comparable](fn func(K) string) Option {
+	return func(cfg *config) {
+		cfg.keyString = fn
+	}
+}
+
+// WithPrefixIndex makes the cache maintain a prefix tree of its keys, so that DeletePrefix visits
+// the matching keys only, instead of scanning the whole cache, at the cost of extra memory and
+// some overhead on every insertion and removal of an item. The option requires string keys,
+// or a function converting the keys to strings (see WithKeyString).
+func WithPrefixIndex() Option {
+	return func(cfg *config) {
+		cfg.prefixIndex = true
+	}
+}
+
+// DeletePrefix evicts all the items with the keys starting with the given prefix, returning
+// the number of items evicted. The items are reported as deleted. The method panics if the keys
+// of the cache are not strings, and there is no function converting them to strings (see
+// WithKeyString).
+func (c *LRU[K, V]) DeletePrefix(prefix string) int {
+	if c.keyString == nil {
+		panic("attempt to delete keys by prefix from a cache with non-string keys")
+	}
+
+	if c.prefixes == nil {
+		return c.removeIf(func(key K) bool { return strings.HasPrefix(c.keyString(key), prefix) })
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	var keys []K
+
+	c.prefixes.walk(prefix, func(key K) { keys = append(keys, key) })
+
+	for _, key := range keys {
+		c.drop(c.nodes[key], EvictDeleted)
+	}
+
+	return len(keys)
+}
+
+// prefix tree of the keys, by their string representations
+type prefixTrie[K comparable] struct {
+	next map[byte]*prefixTrie[K] // children
+	keys []K                     // keys of the string ending at this node
+}
+
+// add the key, unless already there
+func (t *prefixTrie[K]) insert(s string, key K) {
+	for i := 0; i < len(s); i++ {
+		if t.next == nil {
+			t.next = make(map[byte]*prefixTrie[K])
+		}
+
+		child := t.next[s[i]]
+
+		if child == nil {
+			child = &prefixTrie[K]{}
+			t.next[s[i]] = child
+		}
+
+		t = child
+	}
+
+	for _, k := range t.keys {
+		if k == key {
+			return
+		}
+	}
+
+	t.keys = append(t.keys, key)
+}
+
+// delete the key, if present, pruning the empty nodes
+func (t *prefixTrie[K]) delete(s string, key K) {
+	path := make([]*prefixTrie[K], 0, len(s)+1)
+
+	for i := 0; i < len(s); i++ {
+		path = append(path, t)
+
+		if t = t.next[s[i]]; t == nil {
+			return
+		}
+	}
+
+	for i, k := range t.keys {
+		if k == key {
+			last := len(t.keys) - 1
+
+			t.keys[i] = t.keys[last]
+			t.keys[last] = *new(K)
+			t.keys = t.keys[:last]
+			break
+		}
+	}
+
+	// prune the empty nodes, bottom up
+	for i := len(path) - 1; i >= 0 && len(t.keys) == 0 && len(t.next) == 0; i-- {
+		t = path[i]
+		delete(t.next, s[i])
+	}
+}
+
+// invoke the given function for each key starting with the given prefix
+func (t *prefixTrie[K]) walk(prefix string, fn func(K)) {
+	for i := 0; i < len(prefix) && t != nil; i++ {
+		t = t.next[prefix[i]]
+	}
+
+	if t != nil {
+		t.each(fn)
+	}
+}
+
+// invoke the given function for each key in the subtree
+func (t *prefixTrie[K]) each(fn func(K)) {
+	for _, key := range t.keys {
+		fn(key)
+	}
+
+	for _, child := range t.next {
+		child.each(fn)
+	}
+}
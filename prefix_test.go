@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDeletePrefix(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithPrefixIndex()}} {
+		c := New(100, time.Hour, func(key string) (string, error) { return key, nil }, opts...)
+
+		for _, key := range []string{"user/1", "user/1/profile", "user/12", "user/2/profile", "group/1", "user"} {
+			if _, err := c.Get(key); err != nil {
+				t.Error("unexpected error:", err)
+				return
+			}
+		}
+
+		if n := c.DeletePrefix("user/1"); n != 3 {
+			t.Error("unexpected number of deleted keys:", n)
+			return
+		}
+
+		if n := c.DeletePrefix("user/3"); n != 0 {
+			t.Error("unexpected number of deleted keys:", n)
+			return
+		}
+
+		if keys := c.OrderedKeys(); !slices.Equal(slices.Sorted(slices.Values(keys)), []string{"group/1", "user", "user/2/profile"}) {
+			t.Error("unexpected keys:", keys)
+			return
+		}
+
+		if s := c.Stats(); s.EvictedDeleted != 3 {
+			t.Error("unexpected number of deletions:", s.EvictedDeleted)
+			return
+		}
+
+		if n := c.DeletePrefix(""); n != 3 || c.Len() != 0 {
+			t.Error("unexpected number of deleted keys:", n)
+			return
+		}
+
+		if c.prefixes != nil && (len(c.prefixes.next) != 0 || len(c.prefixes.keys) != 0) {
+			t.Error("prefix tree has not been pruned")
+			return
+		}
+	}
+}
+
+func TestDeletePrefixKeyString(t *testing.T) {
+	c := New(100, time.Hour, simpleBackend, WithPrefixIndex(), WithKeyString(strconv.Itoa))
+
+	if err := fill(c.Get, []int{1, 10, 11, 2, 20, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if n := c.DeletePrefix("1"); n != 3 {
+		t.Error("unexpected number of deleted keys:", n)
+		return
+	}
+
+	// the index follows evictions
+	c.Delete(2)
+
+	if n := c.DeletePrefix("2"); n != 1 {
+		t.Error("unexpected number of deleted keys:", n)
+		return
+	}
+
+	if err := checkState(c, []int{3}, validKey); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// non-string keys
+	_, err := TryNew(10, time.Hour, simpleBackend, WithPrefixIndex())
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("missing panic")
+		}
+	}()
+
+	New(10, time.Hour, simpleBackend).DeletePrefix("1")
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// Preload fetches the given keys that are not already in the cache, using up to the given number
+// of concurrent backend calls (at least 1). A key that is being loaded by another goroutine at
+// the same time is not fetched twice. The function stops dispatching the keys when the context
+// is cancelled, in which case it returns the context error; otherwise, it returns the first
+// error from the backend, if any, while still loading all the other keys.
+func (c *LRU[K, V]) Preload(ctx context.Context, keys []K, parallelism int) error {
+	parallelism = max(min(parallelism, len(keys)), 1)
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+
+	queue := make(chan K)
+
+	for range parallelism {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for key := range queue {
+				if _, err := c.Get(key); err != nil {
+					once.Do(func() { first = err })
+				}
+			}
+		}()
+	}
+
+	err := dispatch(ctx, queue, keys)
+
+	close(queue)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	return first
+}
+
+// feed the keys to the queue until done or cancelled
+func dispatch[K comparable](ctx context.Context, queue chan<- K, keys []K) error {
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		select {
+		case queue <- key:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
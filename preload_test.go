@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPreload(t *testing.T) {
+	var calls, running, peak atomic.Int32
+
+	c := New(100, time.Hour, func(key int) (int, error) {
+		calls.Add(1)
+
+		n := running.Add(1)
+
+		defer running.Add(-1)
+
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+
+		time.Sleep(time.Millisecond)
+		return simpleBackend(key)
+	})
+
+	if _, err := c.Get(1); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	keys := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 10, 10}
+
+	if err := c.Preload(context.Background(), keys, 3); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if n := calls.Load(); n != 10 {
+		t.Errorf("unexpected number of backend calls: %d instead of 10", n)
+		return
+	}
+
+	if n := peak.Load(); n > 3 {
+		t.Errorf("too many concurrent backend calls: %d", n)
+		return
+	}
+
+	if len(c.nodes) != 10 {
+		t.Errorf("unexpected cache size: %d instead of 10", len(c.nodes))
+		return
+	}
+
+	// errors
+	if err := c.Preload(context.Background(), []int{11, 1000, 12}, 2); err == nil {
+		t.Error("missing error")
+		return
+	}
+
+	if c.nodes[11] == nil || c.nodes[12] == nil {
+		t.Error("valid keys have not been loaded")
+		return
+	}
+
+	// cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancel()
+
+	if err := c.Preload(ctx, []int{20, 21, 22}, 1); !errors.Is(err, context.Canceled) {
+		t.Error("unexpected error:", err)
+		return
+	}
+}
@@ -0,0 +1,117 @@
+package cache
+
+import "unsafe"
+
+// Priority is the eviction priority of a cache item, where the items of a lower priority are
+// always evicted before the items of a higher priority, regardless of their recency, and the
+// eviction policy of the cache (see WithPolicy) only applies to the items of normal priority.
+// Among the items of low or high priority, the least recently used one is evicted first.
+type Priority int8
+
+const (
+	// PriorityLow is for the items to be evicted first, like best-effort memoisation results.
+	PriorityLow Priority = iota - 1
+
+	// PriorityNormal is the default priority.
+	PriorityNormal
+
+	// PriorityHigh is for the items to be evicted last, like critical configuration objects.
+	PriorityHigh
+)
+
+// WithPriority sets the function to calculate the eviction priority of each item once the value
+// is fetched from the backend, or stored in the cache by any other means, unless the priority
+// is given explicitly (see SetWithPriority). Errors have normal priority. The key and value types
+// of the function must match those of the cache.
+func WithPriority[K comparable, V any](fn func(K, V) Priority) Option {
+	return func(cfg *config) {
+		cfg.priority = fn
+	}
+}
+
+// SetWithPriority stores the given value in the cache as the most recent item of the given
+// priority, with the default TTL, replacing the existing item, if any. The value is never written
+// to the backend (see WithWriter). After the cache has been closed the method does nothing.
+func (c *LRU[K, V]) SetWithPriority(key K, value V, p Priority) {
+	var weight int
+
+	if c.weigher != nil {
+		weight = max(c.weigher(key, value), 0)
+	}
+
+	var tags []string
+
+	if c.tagger != nil {
+		tags = c.tagger(key, value)
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if !c.closed {
+		c.store(key, value, 0, weight, tags, clampPriority(p))
+	}
+}
+
+// priority of the given item
+func (c *LRU[K, V]) priorityOf(key K, value V) Priority {
+	if c.prioritiser == nil {
+		return PriorityNormal
+	}
+
+	return clampPriority(c.prioritiser(key, value))
+}
+
+// the given priority limited to the defined range
+func clampPriority(p Priority) Priority {
+	return min(max(p, PriorityLow), PriorityHigh)
+}
+
+// the LRU list for the unpinned node of its priority
+func (c *LRU[K, V]) home(node *lruNode[K, V]) *listNode {
+	switch node.priority {
+	case PriorityLow:
+		return &c.low
+	case PriorityHigh:
+		return &c.high
+	default:
+		return &c.list
+	}
+}
+
+// change the priority of the node, moving it to the top of the appropriate list (the cache
+// must be locked)
+func (c *LRU[K, V]) setPriority(node *lruNode[K, V], p Priority) {
+	if node.priority == p {
+		return
+	}
+
+	node.priority = p
+
+	if !node.cached() || node.pinned {
+		return
+	}
+
+	node.remove()
+
+	if node.protected {
+		node.protected = false
+		c.numProt--
+	}
+
+	node.addTo(c.home(node))
+}
+
+// the least recent node from the given list, or nil if the list is empty
+func (c *LRU[K, V]) tail(root *listNode) *lruNode[K, V] {
+	if root.prev == root {
+		return nil
+	}
+
+	return (*lruNode[K, V])(unsafe.Pointer(root.prev))
+}
+
+// all the LRU lists in the order of decreasing priority
+func (c *LRU[K, V]) lists() []*listNode {
+	return []*listNode{&c.pinned, &c.high, &c.protected, &c.list, &c.low}
+}
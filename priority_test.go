@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPriority(t *testing.T) {
+	c := New(4, time.Hour, simpleBackend,
+		WithPriority(func(k, _ int) Priority {
+			switch {
+			case k < 10:
+				return PriorityLow
+			case k >= 90:
+				return PriorityHigh
+			default:
+				return PriorityNormal
+			}
+		}))
+
+	// the low priority item goes first, regardless of its recency
+	if err := fill(c.Get, []int{95, 20, 1, 30, 40}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkPriorities(c, []int{95}, []int{20, 30, 40}, nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the high priority item stays, being the least recent one
+	if err := fill(c.Get, []int{50, 60, 70}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkPriorities(c, []int{95}, []int{50, 60, 70}, nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// explicit priority
+	c.SetWithPriority(60, -60, PriorityLow)
+
+	if err := checkPriorities(c, []int{95}, []int{50, 70}, []int{60}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	c.SetWithPriority(3, -3, PriorityHigh)
+
+	if err := checkPriorities(c, []int{95, 3}, []int{50, 70}, nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// with only the high priority items left, the least recent of them is evicted
+	for _, k := range []int{4, 5, 6} {
+		c.SetWithPriority(k, -k, PriorityHigh)
+	}
+
+	if err := checkPriorities(c, []int{3, 4, 5, 6}, nil, nil); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestPriorityPin(t *testing.T) {
+	c := New(2, time.Hour, simpleBackend,
+		WithPriority(func(k, _ int) Priority {
+			if k < 10 {
+				return PriorityLow
+			}
+
+			return PriorityNormal
+		}))
+
+	c.Pin(1)
+
+	if err := fill(c.Get, []int{1, 20, 30}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// unpinned item goes back to its priority list
+	c.Unpin(1)
+
+	if err := checkPriorities(c, nil, []int{30}, []int{1}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := fill(c.Get, []int{40}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkPriorities(c, nil, []int{30, 40}, nil); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+// check the keys of each priority, from least recent to most recent
+func checkPriorities(c *LRU[int, int], high, normal, low []int) error {
+	if n := len(high) + len(normal) + len(low); len(c.nodes) != n {
+		return fmt.Errorf("unexpected size of cache map: %d instead of %d", len(c.nodes), n)
+	}
+
+	if keys := listKeys(&c.high); !slices.Equal(keys, high) {
+		return fmt.Errorf("unexpected high priority keys: %v instead of %v", keys, high)
+	}
+
+	if keys := listKeys(&c.list); !slices.Equal(keys, normal) {
+		return fmt.Errorf("unexpected normal priority keys: %v instead of %v", keys, normal)
+	}
+
+	if keys := listKeys(&c.low); !slices.Equal(keys, low) {
+		return fmt.Errorf("unexpected low priority keys: %v instead of %v", keys, low)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+const (
+	numHitStripes = 16 // number of hit buffers
+	hitBufferSize = 32 // capacity of each hit buffer
+)
+
+// striped buffers of cache hits, for applying promotions in batches (BP-Wrapper)
+type hitBuffers[K comparable, V any] [numHitStripes]hitBuffer[K, V]
+
+// hit buffer
+type hitBuffer[K comparable, V any] struct {
+	mu    sync.Mutex                    // buffer lock
+	n     int                           // number of recorded hits
+	nodes [hitBufferSize]*lruNode[K, V] // recorded hits
+}
+
+// record a cache hit on the node, applying the batch of promotions when the buffer is full
+func (b *hitBuffers[K, V]) record(c *LRU[K, V], node *lruNode[K, V]) {
+	buff := &b[rand.IntN(numHitStripes)]
+
+	buff.mu.Lock()
+
+	buff.nodes[buff.n] = node
+
+	if buff.n++; buff.n < hitBufferSize {
+		buff.mu.Unlock()
+		return
+	}
+
+	// the buffer is full
+	batch := buff.nodes
+	buff.nodes, buff.n = [hitBufferSize]*lruNode[K, V]{}, 0
+
+	buff.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.promote(batch[:])
+}
+
+// apply the buffered promotions, if any, whenever the cache gets locked for an update anyway,
+// so that the recency of the lightly used keys does not stay stale until a buffer fills up
+// (the cache must be locked)
+func (c *LRU[K, V]) applyHits() {
+	if c.lockFree && c.policy != PolicyClock {
+		c.hits.drain(c)
+	}
+}
+
+// apply all the recorded promotions (the cache must be locked)
+func (b *hitBuffers[K, V]) drain(c *LRU[K, V]) {
+	for i := range b {
+		buff := &b[i]
+
+		buff.mu.Lock()
+		batch, n := buff.nodes, buff.n
+		buff.nodes, buff.n = [hitBufferSize]*lruNode[K, V]{}, 0
+		buff.mu.Unlock()
+
+		c.promote(batch[:n])
+	}
+}
+
+// apply a batch of promotions, skipping the nodes that have already left the cache
+func (c *LRU[K, V]) promote(batch []*lruNode[K, V]) {
+	for _, node := range batch {
+		if node.cached() {
+			c.touch(node)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBufferedPromotion(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn, WithBufferedPromotion())
+
+	if err := fill(c.Get, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// the hits are not applied yet
+	if err := checkState(c, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	// the hits are applied before evicting the least recent item
+	if err := fill(c.Get, []int{10}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := checkState(c, []int{2, 3, 4, 5, 6, 7, 8, 9, 0, 10}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	// and on the janitor run
+	if err := fill(c.Get, []int{2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.sweep()
+
+	if err := checkState(c, []int{3, 4, 5, 6, 7, 8, 9, 0, 10, 2}, validKey); err != nil {
+		t.Error("invalid cache state:", err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
+
+func TestBufferedPromotionConcurrent(t *testing.T) {
+	var (
+		backend intBackendMT
+		wg      sync.WaitGroup
+	)
+
+	c := New(50, time.Hour, backend.fn, WithPolicy(PolicySLRU), WithBufferedPromotion())
+
+	wg.Add(4)
+
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20000; j++ {
+				k := rand.Intn(60)
+
+				if rand.Intn(100) == 0 {
+					c.Delete(k)
+				} else if err := getOne(c, k); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hits.drain(c)
+
+	if n := len(listKeys(&c.list)) + len(listKeys(&c.protected)); n != len(c.nodes) {
+		t.Errorf("unexpected number of nodes in the lists: %d instead of %d", n, len(c.nodes))
+		return
+	}
+}
+
+func BenchmarkCacheBuffered(b *testing.B) {
+	const cacheSize = 100
+
+	c := New(cacheSize, time.Hour, simpleBackend, WithBufferedPromotion())
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if err := getOne(c, i%cacheSize); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
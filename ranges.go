@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+)
+
+// WithOrderedIndex makes the cache maintain an ordered index of its keys, so that DeleteRange
+// and RangeAscend visit the keys within the range only, instead of scanning and sorting the whole
+// cache, at the cost of extra memory and O(log n) overhead on every insertion and removal of an
+// item. The key type of the option must match that of the cache.
+func WithOrderedIndex[K cmp.Ordered]() Option {
+	return func(cfg *config) {
+		cfg.orderedIndex = func() keyIndex[K] { return &orderedIndex[K]{} }
+	}
+}
+
+// DeleteRange evicts all the items with the keys in the range [lo, hi) from the given cache with
+// ordered keys, returning the number of items evicted. The items are reported as deleted.
+// Without the ordered index (see WithOrderedIndex) the function scans the whole cache, which
+// takes O(n) time, with the cache locked.
+func DeleteRange[K cmp.Ordered, V any](c *LRU[K, V], lo, hi K) int {
+	index, ok := c.ordered.(*orderedIndex[K])
+
+	if !ok {
+		return c.removeIf(func(key K) bool { return key >= lo && key < hi })
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	var keys []K
+
+	index.ascend(lo, hi, func(key K) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	for _, key := range keys {
+		c.drop(c.nodes[key], EvictDeleted)
+	}
+
+	return len(keys)
+}
+
+// RangeAscend calls the given function for each valid item with the key in the range [lo, hi)
+// in the given cache with ordered keys, in ascending order of the keys, until the function
+// returns false. As with RangeLRU, the function is invoked on a copy of the items made at the
+// start of the call, so it can safely call any methods of the cache. Without the ordered index
+// (see WithOrderedIndex) the function copies and sorts all the items of the cache, which takes
+// O(n log n) time.
+func RangeAscend[K cmp.Ordered, V any](c *LRU[K, V], lo, hi K, fn func(K, V) bool) {
+	var recs []Entry[K, V]
+
+	if index, ok := c.ordered.(*orderedIndex[K]); ok {
+		recs = rangeRecords(c, index, lo, hi)
+	} else {
+		recs = slices.DeleteFunc(c.records(), func(rec Entry[K, V]) bool {
+			return rec.Key < lo || rec.Key >= hi
+		})
+
+		slices.SortFunc(recs, func(a, b Entry[K, V]) int { return cmp.Compare(a.Key, b.Key) })
+	}
+
+	for _, rec := range recs {
+		if !fn(rec.Key, rec.Value) {
+			return
+		}
+	}
+}
+
+// valid items with the keys in the range [lo, hi), in ascending order of the keys
+func rangeRecords[K cmp.Ordered, V any](c *LRU[K, V], index *orderedIndex[K], lo, hi K) (recs []Entry[K, V]) {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	index.ascend(lo, hi, func(key K) bool {
+		node := c.nodes[key]
+
+		// the data fields are only safe to read once the node is ready
+		if node.ready && node.err == nil && c.current(node) && now.Before(node.deadline()) {
+			recs = append(recs, Entry[K, V]{node.key, node.value, node.ts, node.deadline()})
+		}
+
+		return true
+	})
+
+	return
+}
+
+// index of the keys, updated on every insertion and removal of an item
+type keyIndex[K comparable] interface {
+	insert(key K)
+	delete(key K)
+}
+
+// ordered index of the keys: a treap, that is, a binary search tree kept balanced by random
+// priorities of its nodes
+type orderedIndex[K cmp.Ordered] struct {
+	root *treapNode[K]
+}
+
+// node of the treap
+type treapNode[K cmp.Ordered] struct {
+	key         K
+	prio        uint64
+	left, right *treapNode[K]
+}
+
+// add the key, unless already there
+func (x *orderedIndex[K]) insert(key K) {
+	p := &x.root
+
+	// find the position of the new node by its priority
+	prio := rand.Uint64()
+
+	for *p != nil && (*p).prio >= prio {
+		switch t := *p; {
+		case key < t.key:
+			p = &t.left
+		case key > t.key:
+			p = &t.right
+		default:
+			return
+		}
+	}
+
+	// the key may be deeper in the tree
+	for t := *p; t != nil; {
+		switch {
+		case key < t.key:
+			t = t.left
+		case key > t.key:
+			t = t.right
+		default:
+			return
+		}
+	}
+
+	node := &treapNode[K]{key: key, prio: prio}
+
+	node.left, node.right = splitTreap(*p, key)
+	*p = node
+}
+
+// delete the key, if present
+func (x *orderedIndex[K]) delete(key K) {
+	p := &x.root
+
+	for *p != nil {
+		switch t := *p; {
+		case key < t.key:
+			p = &t.left
+		case key > t.key:
+			p = &t.right
+		default:
+			*p = mergeTreap(t.left, t.right)
+			return
+		}
+	}
+}
+
+// invoke the given function for each key in the range [lo, hi), in ascending order, until
+// the function returns false
+func (x *orderedIndex[K]) ascend(lo, hi K, fn func(K) bool) {
+	ascendTreap(x.root, lo, hi, fn)
+}
+
+// in-order traversal of the keys of the subtree within the range [lo, hi), returning false
+// if stopped by the given function
+func ascendTreap[K cmp.Ordered](t *treapNode[K], lo, hi K, fn func(K) bool) bool {
+	if t == nil {
+		return true
+	}
+
+	if lo < t.key && !ascendTreap(t.left, lo, hi, fn) {
+		return false
+	}
+
+	if t.key >= hi {
+		return true
+	}
+
+	if t.key >= lo && !fn(t.key) {
+		return false
+	}
+
+	return ascendTreap(t.right, lo, hi, fn)
+}
+
+// split the subtree into the keys less than the given one, and the keys greater than it
+// (the key itself must not be in the subtree)
+func splitTreap[K cmp.Ordered](t *treapNode[K], key K) (l, r *treapNode[K]) {
+	switch {
+	case t == nil:
+		return nil, nil
+	case t.key < key:
+		t.right, r = splitTreap(t.right, key)
+		return t, r
+	default:
+		l, t.left = splitTreap(t.left, key)
+		return l, t
+	}
+}
+
+// merge the two subtrees, where all the keys of the first one are less than the keys
+// of the second one
+func mergeTreap[K cmp.Ordered](l, r *treapNode[K]) *treapNode[K] {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.prio > r.prio:
+		l.right = mergeTreap(l.right, r)
+		return l
+	default:
+		r.left = mergeTreap(l, r.left)
+		return r
+	}
+}
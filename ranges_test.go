@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRanges(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithOrderedIndex[int]()}} {
+		c := New(10, time.Hour, simpleBackend, opts...)
+
+		if err := fill(c.Get, []int{5, 1, 8, 3, 1000, 7, 2, 9}, validKey); err != nil {
+			t.Error("error filling the cache:", err)
+			return
+		}
+
+		var items []string
+
+		RangeAscend(c, 2, 9, func(key, value int) bool {
+			items = append(items, fmt.Sprintf("%d:%d", key, value))
+			c.Delete(key) // safe to call
+
+			return len(items) < 4
+		})
+
+		if s := fmt.Sprint(items); s != "[2:-2 3:-3 5:-5 7:-7]" {
+			t.Errorf("unexpected items: %s", s)
+			return
+		}
+
+		// errors are deleted as well
+		if n := DeleteRange(c, 8, 2000); n != 3 {
+			t.Error("unexpected number of deleted keys:", n)
+			return
+		}
+
+		if err := checkState(c, []int{1}, validKey); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+func TestOrderedIndex(t *testing.T) {
+	c := New(50, time.Hour, simpleBackend, WithOrderedIndex[int]())
+	index := c.ordered.(*orderedIndex[int])
+
+	for i := 0; i < 10000; i++ {
+		switch k := rand.Intn(100); rand.Intn(10) {
+		case 0:
+			c.Delete(k)
+		case 1:
+			lo := rand.Intn(100)
+			DeleteRange(c, lo, lo+rand.Intn(10))
+		default:
+			if err := getOne(c, k); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		if err := checkOrderedIndex(c, index); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+// validate the ordered index against the cache content
+func checkOrderedIndex(c *LRU[int, int], index *orderedIndex[int]) error {
+	var keys []int
+
+	index.ascend(0, 100, func(key int) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	exp := make([]int, 0, len(c.nodes))
+
+	for key := range c.nodes {
+		exp = append(exp, key)
+	}
+
+	slices.Sort(exp)
+
+	if !slices.Equal(keys, exp) {
+		return fmt.Errorf("index mismatch: %v instead of %v", keys, exp)
+	}
+
+	return checkTreap(index.root)
+}
+
+// check the priority order of the treap nodes
+func checkTreap(t *treapNode[int]) error {
+	if t == nil {
+		return nil
+	}
+
+	for _, child := range []*treapNode[int]{t.left, t.right} {
+		if child != nil && child.prio > t.prio {
+			return fmt.Errorf("priority order violation at key %d", child.key)
+		}
+	}
+
+	if err := checkTreap(t.left); err != nil {
+		return err
+	}
+
+	return checkTreap(t.right)
+}
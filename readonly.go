@@ -0,0 +1,36 @@
+package cache
+
+// ReadOnly is a read-only view of an LRU cache, which never invokes the backend, and never
+// modifies the cache in any way, including the order of eviction and the statistics. The view
+// reflects the current contents of the cache.
+type ReadOnly[K comparable, V any] struct {
+	c *LRU[K, V]
+}
+
+// Freeze returns a read-only view of the cache, for example, to hand over to some code that
+// must not cause any backend traffic, or affect the eviction.
+func (c *LRU[K, V]) Freeze() ReadOnly[K, V] {
+	return ReadOnly[K, V]{c}
+}
+
+// Get returns the value associated with the given key, if the value is in the cache and has
+// not expired. Errors from the backend are not returned.
+func (v ReadOnly[K, V]) Get(key K) (value V, found bool) {
+	c := v.c
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.nodes[key]
+
+	if node == nil || !node.ready || node.err != nil || !c.fresh(node, c.now()) {
+		return
+	}
+
+	return node.value, true
+}
+
+// Len returns the current number of items in the cache (see LRU.Len).
+func (v ReadOnly[K, V]) Len() int {
+	return v.c.Len()
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreeze(t *testing.T) {
+	var backend tracingBackend
+
+	now := time.Now()
+
+	c := New(3, time.Minute, backend.fn, WithClock(func() time.Time { return now }))
+
+	if err := fill(c.Get, []int{1, 2, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	view := c.Freeze()
+
+	if v, found := view.Get(1); !found || v != -1 {
+		t.Error("unexpected result:", v, found)
+		return
+	}
+
+	// no values for errors or missing keys
+	for _, key := range []int{1000, 3} {
+		if _, found := view.Get(key); found {
+			t.Error("unexpected value for key", key)
+			return
+		}
+	}
+
+	// the view has not affected the order of eviction
+	if err := fill(c.Get, []int{4, 2}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if _, found := view.Get(1); found {
+		t.Error("unexpected value for an evicted key")
+		return
+	}
+
+	// expired items
+	now = now.Add(time.Hour)
+
+	if _, found := view.Get(2); found {
+		t.Error("unexpected value for an expired key")
+		return
+	}
+
+	if n := view.Len(); n != 3 {
+		t.Error("unexpected number of items:", n)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2, 1000, 4}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 4 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+}
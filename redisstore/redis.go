@@ -0,0 +1,85 @@
+// Package redisstore provides Redis implementation of the second-level cache store.
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/maxim2266/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is an implementation of cache.Store interface on top of Redis.
+type Store[K comparable, V any] struct {
+	client redis.UniversalClient // Redis client
+	codec  cache.Codec[K, V]     // codec for the values
+	prefix string                // prefix for all Redis keys
+}
+
+// New creates a new Redis store with keys of type "K" and values of type "V", using the given
+// client. Each cache key is mapped to the Redis key by formatting it with fmt.Sprint and adding
+// the given prefix, and each cache entry is stored as a string encoded by the given codec.
+func New[K comparable, V any](client redis.UniversalClient, codec cache.Codec[K, V], prefix string) *Store[K, V] {
+	if client == nil {
+		panic("attempt to create a Redis store with nil client")
+	}
+
+	if codec == nil {
+		panic("attempt to create a Redis store with nil codec")
+	}
+
+	return &Store[K, V]{client, codec, prefix}
+}
+
+// Get implements cache.Store interface.
+func (s *Store[K, V]) Get(ctx context.Context, key K) (value V, found bool, err error) {
+	data, err := s.client.Get(ctx, s.key(key)).Bytes()
+
+	switch err {
+	case nil:
+		// ok
+	case redis.Nil:
+		return value, false, nil
+	default:
+		return
+	}
+
+	var e cache.Entry[K, V]
+
+	switch err = s.codec.Decode(data, &e); err {
+	case nil:
+		return e.Value, true, nil
+	case cache.ErrSkipEntry:
+		return value, false, nil
+	default:
+		return
+	}
+}
+
+// Set implements cache.Store interface. The Redis key expires after the given TTL.
+// An entry rejected by the codec with cache.ErrSkipEntry is not stored.
+func (s *Store[K, V]) Set(ctx context.Context, key K, value V, ttl time.Duration) error {
+	now := time.Now()
+
+	data, err := s.codec.Encode(&cache.Entry[K, V]{Key: key, Value: value, Time: now, Expires: now.Add(ttl)})
+
+	switch err {
+	case nil:
+		return s.client.Set(ctx, s.key(key), data, ttl).Err()
+	case cache.ErrSkipEntry:
+		return nil
+	default:
+		return err
+	}
+}
+
+// Delete implements cache.Store interface.
+func (s *Store[K, V]) Delete(ctx context.Context, key K) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
+
+// Redis key for the given cache key
+func (s *Store[K, V]) key(key K) string {
+	return s.prefix + fmt.Sprint(key)
+}
@@ -0,0 +1,95 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/maxim2266/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStore(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	defer client.Close()
+
+	ctx := context.Background()
+	store := New(client, cache.JSONCodec[int, string]{}, "test:")
+
+	if _, found, err := store.Get(ctx, 1); err != nil || found {
+		t.Error("unexpected result:", found, err)
+		return
+	}
+
+	if err := store.Set(ctx, 1, "one", time.Minute); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if v, found, err := store.Get(ctx, 1); err != nil || !found || v != "one" {
+		t.Error("unexpected result:", v, found, err)
+		return
+	}
+
+	if ttl := srv.TTL("test:1"); ttl != time.Minute {
+		t.Error("unexpected TTL:", ttl)
+		return
+	}
+
+	// expiration
+	srv.FastForward(time.Minute)
+
+	if _, found, err := store.Get(ctx, 1); err != nil || found {
+		t.Error("unexpected result:", found, err)
+		return
+	}
+
+	// deletion
+	if err := store.Set(ctx, 2, "two", time.Minute); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err := store.Delete(ctx, 2); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if srv.Exists("test:2") {
+		t.Error("the key has not been deleted")
+		return
+	}
+}
+
+func TestTiered(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	defer client.Close()
+
+	var calls int
+
+	backend := func(key int) (int, error) {
+		calls++
+		return -key, nil
+	}
+
+	store := New(client, cache.JSONCodec[int, int]{}, "")
+	c1 := cache.NewTiered(10, time.Hour, store, backend)
+	c2 := cache.NewTiered(10, time.Hour, store, backend)
+
+	for _, c := range []*cache.Tiered[int, int]{c1, c2} {
+		if v, err := c.Get(5); err != nil || v != -5 {
+			t.Error("unexpected result:", v, err)
+			return
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("unexpected number of backend calls: %d instead of 1", calls)
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// check if the expired node can be served while being reloaded
+func (c *LRU[K, V]) stale(node *lruNode[K, V], now time.Time) bool {
+	return c.maxStale > 0 && node.ready && node.err == nil &&
+		now.Sub(node.deadline()) < c.maxStale
+}
+
+// check if the node is due for refresh-ahead
+func (c *LRU[K, V]) due(node *lruNode[K, V], now time.Time) bool {
+	return c.ahead > 0 && node.ready && now.Sub(node.ts) >= time.Duration(float64(node.lifetime())*c.ahead)
+}
+
+// reload the node in the background, replacing it with the fresh one when ready
+// (a failed reload keeps the old node if errors are not to be cached)
+func (c *LRU[K, V]) refresh(old *lruNode[K, V]) {
+	node := c.newNode(old.key, c.now())
+
+	c.acquire(old)
+
+	go func() {
+		defer func() {
+			// the panic has already been recorded in the node error, and there
+			// is nobody to propagate it to
+			recover()
+
+			c.mu.Lock()
+			defer c.unlock()
+			defer c.release(old)
+
+			old.refreshing = false
+
+			switch {
+			case c.nodes[old.key] == old && !node.discard:
+				c.replace(old, node)
+			case node.ready && node.err == nil && !node.discard:
+				// the old node is gone, so the fresh one is dropped
+				c.notify(node, old.evicted)
+			}
+		}()
+
+		node.once.Do(func() { c.load(node, c.revalidator(old)) })
+	}()
+}
+
+// replace the old node with the new one, at the same position in the LRU list, unless
+// the priority has changed
+func (c *LRU[K, V]) replace(old, node *lruNode[K, V]) {
+	node.pinned = old.pinned
+
+	switch {
+	case node.pinned || node.priority == old.priority:
+		node.addTo(old.prev)
+
+		if node.protected = old.protected; node.protected {
+			c.numProt++ // compensate for the unlink below
+		}
+	default:
+		node.addTo(c.home(node))
+	}
+
+	c.unlink(old, EvictReplaced)
+	heap.Push(&c.expiry, node)
+	c.mapNode(node)
+	c.tag(node)
+
+	if node.err == nil {
+		c.deliver(node.key, node.value)
+	}
+
+	c.weight += node.weight
+	c.trim()
+}
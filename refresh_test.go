@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var calls int32
+
+	release := make(chan struct{})
+
+	backend := func(key int) (int, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			<-release
+		}
+
+		return int(atomic.LoadInt32(&calls)), nil
+	}
+
+	c := New(10, 20*time.Millisecond, backend, WithStaleWhileRevalidate(time.Hour))
+
+	if v, err := c.Get(1); err != nil || v != 1 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// stale value while the backend is blocked
+	for i := 0; i < 3; i++ {
+		if v, err := c.Get(1); err != nil || v != 1 {
+			t.Errorf("unexpected stale result: (%d, %v)", v, err)
+			return
+		}
+	}
+
+	close(release)
+
+	// wait for the refreshed value
+	for ts := time.Now(); time.Since(ts) < 10*time.Millisecond; time.Sleep(time.Millisecond) {
+		v, err := c.Get(1)
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		if v == 2 {
+			if n := atomic.LoadInt32(&calls); n != 2 {
+				t.Errorf("unexpected number of backend calls: %d instead of 2", n)
+			}
+
+			return
+		}
+	}
+
+	t.Error("the value has not been refreshed")
+}
+
+func TestRefreshAhead(t *testing.T) {
+	var calls int32
+
+	backend := func(key int) (int, error) {
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}
+
+	c := New(10, 100*time.Millisecond, backend, WithRefreshAhead(0.5))
+
+	if v, err := c.Get(1); err != nil || v != 1 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// the current value is served, while the refresh is in progress
+	if v, err := c.Get(1); err != nil || v != 1 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	// wait for the refreshed value
+	for ts := time.Now(); time.Since(ts) < 30*time.Millisecond; time.Sleep(time.Millisecond) {
+		v, err := c.Get(1)
+
+		if err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+
+		if v == 2 {
+			return
+		}
+	}
+
+	t.Error("the value has not been refreshed")
+}
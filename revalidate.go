@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnchanged is returned from the backend when reloading an item in the background (see
+// WithRefreshAhead and WithStaleWhileRevalidate), possibly wrapped, to report that the value
+// in the cache is still current, so that the value is kept, with its time-to-live restarted.
+// On a cache miss there is no value to keep, and the error is returned to the callers as is.
+var ErrUnchanged = errors.New("value unchanged")
+
+// WithAdaptiveTTL makes the time-to-live of each item adapt to the observed rate of change
+// of its value, within the given bounds: every background reload (see WithRefreshAhead and
+// WithStaleWhileRevalidate) where the backend reports the value unchanged (see ErrUnchanged)
+// doubles the TTL of the item, while every reload returning a new value halves it. The TTL
+// given to the constructor is the initial one, and must be within the bounds, while the TTL
+// returned from the backend (see NewWithTTL), if any, takes precedence. An item reloaded on
+// a cache miss starts again with the initial TTL. The option requires either WithRefreshAhead
+// or WithStaleWhileRevalidate.
+func WithAdaptiveTTL(minTTL, maxTTL time.Duration) Option {
+	return func(cfg *config) {
+		cfg.minTTL, cfg.maxTTL = minTTL, maxTTL
+	}
+}
+
+// backend function for reloading the old node in the background, with the TTL adapted
+// to the change of the value
+func (c *LRU[K, V]) revalidator(old *lruNode[K, V]) func(K) (V, time.Duration, error) {
+	if c.maxTTL == 0 || old.err != nil {
+		return c.backend
+	}
+
+	return func(key K) (value V, ttl time.Duration, err error) {
+		value, ttl, err = c.backend(key)
+
+		switch {
+		case err == nil:
+			if ttl == 0 { // changed
+				ttl = max(old.lifetime()/2, c.minTTL)
+			}
+		case errors.Is(err, ErrUnchanged):
+			value, ttl, err = old.value, min(old.lifetime()*2, c.maxTTL), nil
+		}
+
+		return
+	}
+}
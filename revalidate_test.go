@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTTL(t *testing.T) {
+	var (
+		now       atomic.Int64
+		calls     atomic.Int32
+		unchanged atomic.Bool
+	)
+
+	clock := func() time.Time { return time.Unix(0, now.Load()) }
+	advance := func(d time.Duration) { now.Add(int64(d)) }
+
+	backend := func(key int) (int, error) {
+		n := calls.Add(1)
+
+		if n > 1 && unchanged.Load() {
+			return 0, ErrUnchanged
+		}
+
+		return int(n), nil
+	}
+
+	c := New(10, time.Hour, backend,
+		WithClock(clock),
+		WithRefreshAhead(0.5),
+		WithAdaptiveTTL(15*time.Minute, 3*time.Hour))
+
+	// wait for the background reload of key 1 to produce the given value and TTL
+	reloaded := func(value int, ttl time.Duration) error {
+		expires := clock().Add(ttl)
+
+		for ts := time.Now(); time.Since(ts) < time.Second; time.Sleep(time.Millisecond) {
+			if v, t, found := c.GetStale(1); found && v == value && t.Add(ttl).Equal(expires) {
+				return nil
+			}
+		}
+
+		return errors.New("the value has not been reloaded")
+	}
+
+	if v, err := c.Get(1); err != nil || v != 1 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	// unchanged value: the TTL doubles, up to the limit
+	unchanged.Store(true)
+
+	for _, ttl := range []time.Duration{2 * time.Hour, 3 * time.Hour} {
+		advance(ttl * 3 / 8) // 3/4 of the current TTL
+
+		if v, err := c.Get(1); err != nil || v != 1 {
+			t.Errorf("unexpected result: (%d, %v)", v, err)
+			return
+		}
+
+		if err := reloaded(1, ttl); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// changed value: the TTL halves
+	unchanged.Store(false)
+	advance(2 * time.Hour)
+
+	if _, err := c.Get(1); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err := reloaded(4, 90*time.Minute); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if s := c.Stats(); s.LoadErrors != 0 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+
+	// invalid options
+	if _, err := TryNew(10, time.Hour, backend, WithAdaptiveTTL(time.Minute, time.Hour)); err == nil {
+		t.Error("missing error for adaptive TTL without background reloads")
+		return
+	}
+
+	if _, err := TryNew(10, time.Hour, backend, WithRefreshAhead(0.5),
+		WithAdaptiveTTL(2*time.Hour, 3*time.Hour)); err == nil {
+		t.Error("missing error for invalid adaptive TTL bounds")
+		return
+	}
+}
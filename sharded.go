@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"errors"
+	"hash/maphash"
+	"strconv"
+	"time"
+)
+
+// Sharded is an opaque type representing a cache partitioned into a number of independent
+// LRU caches (shards), with keys of type "K" and values of type "V". Each key is mapped to
+// its shard by hash, so the contention on the lock of any single shard is reduced.
+type Sharded[K comparable, V any] struct {
+	shards []*LRU[K, V] // shards
+	seed   maphash.Seed // hash seed
+	last   lastStats    // statistics at the previous call to StatsDelta
+}
+
+// NewSharded creates a new sharded cache with keys of type "K" and values of type "V".
+// The total capacity of the cache is split between the shards as evenly as possible, with
+// at least one item per shard, while all the other parameters and options apply to each shard
+// independently.
+func NewSharded[K comparable, V any](
+	shards int,
+	size int,
+	ttl time.Duration,
+	backend func(K) (V, error),
+	opts ...Option,
+) *Sharded[K, V] {
+	if shards < 1 || shards > size {
+		panic("attempt to create a sharded cache with invalid number of shards " +
+			strconv.Itoa(shards) + " for the capacity of " + strconv.Itoa(size) + " items")
+	}
+
+	c := &Sharded[K, V]{
+		shards: make([]*LRU[K, V], shards),
+		seed:   maphash.MakeSeed(),
+	}
+
+	// the remainder of the capacity goes to the first shards
+	for i := range c.shards {
+		n := size / shards
+
+		if i < size%shards {
+			n++
+		}
+
+		c.shards[i] = New(n, ttl, backend, opts...)
+	}
+
+	return c
+}
+
+// Get retrieves the value associated with the given key, invoking backend where necessary.
+func (c *Sharded[K, V]) Get(key K) (V, error) {
+	return c.shard(key).Get(key)
+}
+
+// Delete evicts the given key from the cache.
+func (c *Sharded[K, V]) Delete(key K) {
+	c.shard(key).Delete(key)
+}
+
+// DeleteAndWait evicts the given key from the cache, waiting for its load in progress, if any,
+// to complete (see LRU.DeleteAndWait).
+func (c *Sharded[K, V]) DeleteAndWait(key K) {
+	c.shard(key).DeleteAndWait(key)
+}
+
+// DeletePrefix evicts all the items with the keys starting with the given prefix from all
+// the shards, returning the number of items evicted (see LRU.DeletePrefix).
+func (c *Sharded[K, V]) DeletePrefix(prefix string) (n int) {
+	for _, shard := range c.shards {
+		n += shard.DeletePrefix(prefix)
+	}
+
+	return
+}
+
+// Len returns the current number of items in all the shards.
+func (c *Sharded[K, V]) Len() (n int) {
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+
+	return
+}
+
+// Stats returns the aggregated statistics of all the shards, where the recent hit ratio
+// (see WithHitRatioWindow) is the average over the shards.
+func (c *Sharded[K, V]) Stats() (s Stats) {
+	for _, shard := range c.shards {
+		ss := shard.Stats()
+
+		s.add(ss)
+		s.RecentHitRatio += ss.RecentHitRatio
+	}
+
+	s.RecentHitRatio /= float64(len(c.shards))
+	return
+}
+
+// StatsDelta returns the aggregated statistics with the counters accumulated since the previous
+// call to the method (see LRU.StatsDelta).
+func (c *Sharded[K, V]) StatsDelta() Stats {
+	return c.last.delta(c.Stats)
+}
+
+// ShardStats returns the statistics of each shard, for detecting skew in the distribution
+// of the keys.
+func (c *Sharded[K, V]) ShardStats() []Stats {
+	stats := make([]Stats, len(c.shards))
+
+	for i, shard := range c.shards {
+		stats[i] = shard.Stats()
+	}
+
+	return stats
+}
+
+// ExpireNow removes all the expired items from all the shards (see LRU.ExpireNow), returning
+// the number of the items removed.
+func (c *Sharded[K, V]) ExpireNow() (n int) {
+	for _, shard := range c.shards {
+		n += shard.ExpireNow()
+	}
+
+	return
+}
+
+// InvalidateAll makes all the items currently in the cache invalid (see LRU.InvalidateAll).
+func (c *Sharded[K, V]) InvalidateAll() {
+	for _, shard := range c.shards {
+		shard.InvalidateAll()
+	}
+}
+
+// Close closes all the shards (see LRU.Close), returning the errors from all the shards, if any.
+func (c *Sharded[K, V]) Close() error {
+	var errs []error
+
+	for _, shard := range c.shards {
+		if err := shard.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// find the shard for the given key
+func (c *Sharded[K, V]) shard(key K) *LRU[K, V] {
+	return c.shards[maphash.Comparable(c.seed, key)%uint64(len(c.shards))]
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSharded(t *testing.T) {
+	var backend intBackendMT
+
+	c := NewSharded(4, 100, time.Hour, backend.fn)
+
+	defer c.Close()
+
+	keys := make([]int, 0, 100)
+
+	for k := 0; k < 100; k++ {
+		keys = append(keys, k, 1000+k)
+	}
+
+	if err := fill(c.Get, keys, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// each key must land in exactly one shard
+	total := 0
+
+	for _, shard := range c.shards {
+		for k := range shard.nodes {
+			if c.shard(k) != shard {
+				t.Errorf("key %d is in the wrong shard", k)
+				return
+			}
+		}
+
+		total += len(shard.nodes)
+	}
+
+	if total == 0 || total > 100 {
+		t.Errorf("unexpected total number of items: %d", total)
+		return
+	}
+
+	c.Delete(keys[len(keys)-1])
+
+	if _, found := c.shard(keys[len(keys)-1]).nodes[keys[len(keys)-1]]; found {
+		t.Error("the key has not been deleted")
+		return
+	}
+}
+
+func TestShardedCapacity(t *testing.T) {
+	for _, size := range []int{1, 3, 10, 101} {
+		c := NewSharded(min(size, 4), size, time.Hour, simpleBackend)
+
+		total := 0
+
+		for _, shard := range c.shards {
+			total += shard.size
+		}
+
+		if total != size {
+			t.Errorf("unexpected total capacity for size %d: %d", size, total)
+			return
+		}
+	}
+}
+
+func TestShardedStats(t *testing.T) {
+	c := NewSharded(4, 100, time.Hour, simpleBackend)
+
+	defer c.Close()
+
+	if err := fill(c.Get, []int{1, 2, 3, 1000, 1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	var sum Stats
+
+	for _, s := range c.ShardStats() {
+		if s.Capacity != 25 {
+			t.Errorf("unexpected shard capacity: %d instead of 25", s.Capacity)
+			return
+		}
+
+		sum.add(s)
+	}
+
+	exp := Stats{Hits: 2, Misses: 4, Loads: 4, LoadErrors: 1, Size: 4, Capacity: 100}
+
+	if s := c.Stats(); withoutLatency(s) != exp || withoutLatency(sum) != exp || s.LoadLatency != sum.LoadLatency {
+		t.Errorf("unexpected stats: %+v and %+v instead of %+v", s, sum, exp)
+		return
+	}
+}
+
+func BenchmarkShardedContended(b *testing.B) {
+	c := NewSharded(8, 256, time.Hour, simpleBackend)
+
+	b.RunParallel(func(pb *testing.PB) {
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+		for pb.Next() {
+			k := rnd.Intn(100)
+
+			if v, err := c.Get(k); err != nil || v != -k {
+				b.Errorf("unexpected result for key %d: (%d, %v)", k, v, err)
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Sizer is an optional interface for keys and values to report their approximate size
+// in bytes, overriding the built-in estimation used by the WithMaxBytes option.
+type Sizer interface {
+	Size() int
+}
+
+// build weigher function that estimates memory consumption of each cache item
+func byteWeigher[K comparable, V any]() func(K, V) int {
+	// fixed part: the node (with key and value inline), plus the map entry
+	overhead := int(unsafe.Sizeof(lruNode[K, V]{})) + int(unsafe.Sizeof(*new(K))) +
+		int(unsafe.Sizeof(uintptr(0)))
+
+	return func(key K, value V) int {
+		return overhead + extraSize(key) + extraSize(value)
+	}
+}
+
+// size of the memory referenced by the value, not counting the value itself
+func extraSize(x any) int {
+	if s, ok := x.(Sizer); ok {
+		return s.Size()
+	}
+
+	return indirectSize(reflect.ValueOf(x), make(map[uintptr]bool))
+}
+
+// size of the memory referenced by the value, not counting the value itself;
+// "seen" is for counting each pointer only once
+func indirectSize(v reflect.Value, seen map[uintptr]bool) (n int) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len()
+
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+
+		seen[v.Pointer()] = true
+		n = v.Cap() * int(v.Type().Elem().Size())
+
+		if !flat(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += indirectSize(v.Index(i), seen)
+			}
+		}
+
+	case reflect.Array:
+		if !flat(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += indirectSize(v.Index(i), seen)
+			}
+		}
+
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+
+		seen[v.Pointer()] = true
+		n = v.Len() * int(v.Type().Key().Size()+v.Type().Elem().Size())
+
+		for it := v.MapRange(); it.Next(); {
+			n += indirectSize(it.Key(), seen) + indirectSize(it.Value(), seen)
+		}
+
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+
+		seen[v.Pointer()] = true
+		n = int(v.Type().Elem().Size()) + indirectSize(v.Elem(), seen)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+
+		v = v.Elem()
+		n = int(v.Type().Size()) + indirectSize(v, seen)
+
+	case reflect.Struct:
+		if !flat(v.Type()) {
+			for i := 0; i < v.NumField(); i++ {
+				n += indirectSize(v.Field(i), seen)
+			}
+		}
+	}
+
+	return
+}
+
+// check if the type does not refer to any other memory
+func flat(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+
+	case reflect.Array:
+		return flat(t.Elem())
+
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !flat(t.Field(i).Type) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtraSize(t *testing.T) {
+	type node struct {
+		name string
+		next *node
+	}
+
+	cyclic := &node{name: "abc"}
+	cyclic.next = cyclic
+
+	cases := []struct {
+		value any
+		size  int
+	}{
+		{42, 0},
+		{"hello", 5},
+		{make([]byte, 10, 100), 100},
+		{[]string{"a", "bc"}, 2*16 + 3},
+		{map[int]int{1: 2}, 16},
+		{cyclic, 24 + 3},
+		{[2]any{1, nil}, 8},
+		{sizedValue(1000), 1000},
+	}
+
+	for i, c := range cases {
+		if n := extraSize(c.value); n != c.size {
+			t.Errorf("[%d] unexpected size of %T: %d instead of %d", i, c.value, n, c.size)
+			return
+		}
+	}
+}
+
+type sizedValue int
+
+func (v sizedValue) Size() int {
+	return int(v)
+}
+
+func TestMaxBytes(t *testing.T) {
+	const itemSize = 10000
+
+	c := New(100, time.Hour, func(key int) (string, error) {
+		return strings.Repeat("x", itemSize), nil
+	}, WithMaxBytes(5*itemSize))
+
+	for k := 0; k < 10; k++ {
+		if _, err := c.Get(k); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	if n := len(c.nodes); n != 4 {
+		t.Errorf("unexpected number of items: %d instead of 4", n)
+		return
+	}
+
+	if c.weight > 5*itemSize || c.weight < 4*itemSize {
+		t.Errorf("unexpected total size: %d", c.weight)
+		return
+	}
+}
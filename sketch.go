@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"sync"
+)
+
+// WithFrequencySketch enables tracking of the approximate request frequency of each key (see the
+// EstimatedFrequency method), using a count-min sketch sized for the given number of distinct keys,
+// typically the capacity of the cache. The counters saturate at 15, and are all halved every time
+// the number of requests reaches 10 times the size of the sketch, so that the frequencies reflect
+// the recent history. The sketch takes about 4 bytes per key, and requires a separate lock on every
+// request, however short.
+func WithFrequencySketch(n int) Option {
+	return func(cfg *config) {
+		cfg.sketch = n
+	}
+}
+
+// EstimatedFrequency returns the estimated number of recent requests for the given key, never
+// less than the actual number within the current aging period (see WithFrequencySketch), or 0
+// if the cache has been created without the option.
+func (c *LRU[K, V]) EstimatedFrequency(key K) int {
+	if c.freq == nil {
+		return 0
+	}
+
+	return c.freq.estimate(key)
+}
+
+const (
+	sketchDepth  = 4  // number of rows in the sketch
+	sketchMaxVal = 15 // max. value of a counter
+)
+
+// count-min sketch with aging
+type freqSketch[K comparable] struct {
+	mu     sync.Mutex           // protects the sketch
+	rows   [sketchDepth][]uint8 // counters
+	mask   uint64               // counter index mask
+	count  int                  // number of increments since the last aging
+	period int                  // number of increments between agings
+	seed   maphash.Seed         // hash seed
+}
+
+// create a new sketch for the given number of keys
+func newFreqSketch[K comparable](n int) *freqSketch[K] {
+	// row width rounded up to a power of 2
+	width := max(uint64(1)<<bits.Len64(uint64(n)-1), 16)
+
+	s := &freqSketch[K]{
+		mask:   width - 1,
+		period: 10 * int(width),
+		seed:   maphash.MakeSeed(),
+	}
+
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+
+	return s
+}
+
+// count a request for the key
+func (s *freqSketch[K]) record(key K) {
+	h1, h2 := s.hash(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, row := range s.rows {
+		if p := &row[(h1+uint64(i)*h2)&s.mask]; *p < sketchMaxVal {
+			*p++
+		}
+	}
+
+	if s.count++; s.count >= s.period {
+		s.age()
+	}
+}
+
+// estimated frequency of the key
+func (s *freqSketch[K]) estimate(key K) int {
+	h1, h2 := s.hash(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := uint8(sketchMaxVal)
+
+	for i, row := range s.rows {
+		n = min(n, row[(h1+uint64(i)*h2)&s.mask])
+	}
+
+	return int(n)
+}
+
+// halve all the counters
+func (s *freqSketch[K]) age() {
+	for _, row := range s.rows {
+		for i := range row {
+			row[i] >>= 1
+		}
+	}
+
+	s.count /= 2
+}
+
+// pair of hashes of the key, for double hashing
+func (s *freqSketch[K]) hash(key K) (uint64, uint64) {
+	h := maphash.Comparable(s.seed, key)
+
+	return h, h>>32 | h<<32 | 1
+}
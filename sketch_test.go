@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrequencySketch(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend, WithFrequencySketch(100))
+
+	// key 1 is requested 8 times, key 2 twice, the others once
+	keys := []int{1, 2, 1, 1, 2, 1, 1, 1, 1, 1}
+
+	for i := 10; i < 50; i++ {
+		keys = append(keys, i)
+	}
+
+	if err := fill(c.Get, keys, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	// the estimates are never below the actual counts
+	if n := c.EstimatedFrequency(1); n < 8 {
+		t.Error("unexpected frequency of key 1:", n)
+		return
+	}
+
+	if n := c.EstimatedFrequency(2); n < 2 {
+		t.Error("unexpected frequency of key 2:", n)
+		return
+	}
+
+	// aging
+	c.freq.mu.Lock()
+	c.freq.age()
+	c.freq.mu.Unlock()
+
+	if n := c.EstimatedFrequency(1); n < 4 || n > 7 {
+		t.Error("unexpected frequency of key 1 after aging:", n)
+		return
+	}
+
+	// no tracking without the option
+	if n := New(10, time.Hour, simpleBackend).EstimatedFrequency(1); n != 0 {
+		t.Error("unexpected frequency without the sketch:", n)
+		return
+	}
+}
+
+func TestFrequencySketchAging(t *testing.T) {
+	s := newFreqSketch[int](16)
+
+	for range 20 {
+		s.record(1)
+	}
+
+	if n := s.estimate(1); n != sketchMaxVal {
+		t.Error("unexpected saturated frequency:", n)
+		return
+	}
+
+	// the counters are halved after 160 requests
+	for i := range 140 {
+		s.record(100 + i)
+	}
+
+	if s.count != 80 {
+		t.Error("unexpected request count:", s.count)
+		return
+	}
+
+	if n := s.estimate(1); n < 7 {
+		t.Error("unexpected frequency after aging:", n)
+		return
+	}
+}
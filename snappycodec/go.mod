@@ -0,0 +1,10 @@
+module github.com/maxim2266/cache/snappycodec
+
+go 1.24
+
+require (
+	github.com/golang/snappy v1.0.0
+	github.com/maxim2266/cache v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/maxim2266/cache => ..
@@ -0,0 +1,31 @@
+// Package snappycodec provides a value codec compressing the values with Snappy
+// (see cache.NewEncoded).
+package snappycodec
+
+import (
+	"github.com/golang/snappy"
+	"github.com/maxim2266/cache"
+)
+
+// Wrap returns the codec compressing the output of the given codec with Snappy, which is
+// much faster than gzip, at the cost of a lower compression ratio.
+func Wrap[V any](codec cache.ValueCodec[V]) cache.ValueCodec[V] {
+	return cache.ValueCodec[V]{
+		Encode: func(value V) ([]byte, error) {
+			data, err := codec.Encode(value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return snappy.Encode(nil, data), nil
+		},
+		Decode: func(data []byte) (value V, err error) {
+			if data, err = snappy.Decode(nil, data); err == nil {
+				value, err = codec.Decode(data)
+			}
+
+			return
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package snappycodec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxim2266/cache"
+)
+
+func TestCodec(t *testing.T) {
+	codec := Wrap(cache.JSONValues[string]())
+	value := strings.Repeat("abc", 1000)
+
+	data, err := codec.Encode(value)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if len(data) >= len(value) {
+		t.Errorf("data not compressed: %d bytes", len(data))
+		return
+	}
+
+	res, err := codec.Decode(data)
+
+	if err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if res != value {
+		t.Error("value mismatch")
+		return
+	}
+
+	if _, err = codec.Decode([]byte("garbage")); err == nil {
+		t.Error("missing error")
+		return
+	}
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+// Snapshot writes all the valid (i.e., successfully fetched and not expired) items of the cache
+// to the given writer, as a stream of gob-encoded records holding the key, the value, and
+// the time the value was fetched and expires, from the most to the least recently used.
+// Both key and value types must be encodable by encoding/gob. The cache is locked only while
+// copying the items, not while encoding them.
+func (c *LRU[K, V]) Snapshot(w io.Writer) error {
+	enc := gob.NewEncoder(w)
+
+	for _, rec := range c.records() {
+		if err := enc.Encode(&rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Restore reads the items written by Snapshot from the given reader, and adds them to the cache
+// in their original LRU order, skipping the items that have expired since, or are already in
+// the cache. The items keep their original timestamps and expiration times. Restoring more items
+// than the cache capacity evicts the least recently used ones.
+func (c *LRU[K, V]) Restore(r io.Reader) error {
+	var recs []Entry[K, V]
+
+	dec := gob.NewDecoder(r)
+
+	for {
+		var rec Entry[K, V]
+
+		switch err := dec.Decode(&rec); err {
+		case nil:
+			recs = append(recs, rec)
+		case io.EOF:
+			c.restore(recs)
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// Entry is a cache item as stored in a snapshot.
+type Entry[K comparable, V any] struct {
+	Key     K         // key
+	Value   V         // value
+	Time    time.Time // time the value was fetched
+	Expires time.Time // expiration time
+}
+
+// copy the valid items in LRU order, most recent first
+func (c *LRU[K, V]) records() []Entry[K, V] {
+	now := c.now()
+	entries := c.entries()
+	recs := make([]Entry[K, V], 0, len(entries))
+
+	for _, e := range entries {
+		if e.ready && e.err == nil && now.Before(e.deadline) {
+			recs = append(recs, Entry[K, V]{e.key, e.value, e.ts, e.deadline})
+		}
+	}
+
+	return recs
+}
+
+// add the records to the cache, most recent first
+func (c *LRU[K, V]) restore(recs []Entry[K, V]) {
+	weights := make([]int, len(recs))
+
+	if c.weigher != nil {
+		for i, rec := range recs {
+			weights[i] = max(c.weigher(rec.Key, rec.Value), 0)
+		}
+	}
+
+	tags := make([][]string, len(recs))
+
+	if c.tagger != nil {
+		for i, rec := range recs {
+			tags[i] = c.tagger(rec.Key, rec.Value)
+		}
+	}
+
+	priorities := make([]Priority, len(recs))
+
+	for i, rec := range recs {
+		priorities[i] = c.priorityOf(rec.Key, rec.Value)
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return
+	}
+
+	now := c.now()
+
+	// the least recent item goes first, to end up at the bottom of the LRU list
+	for i := len(recs) - 1; i >= 0; i-- {
+		rec := &recs[i]
+
+		if !now.Before(rec.Expires) || c.nodes[rec.Key] != nil {
+			continue
+		}
+
+		if len(c.nodes) >= c.size {
+			c.evict()
+		}
+
+		node := c.newNode(rec.Key, rec.Time)
+
+		node.value, node.weight, node.tags = rec.Value, weights[i], tags[i]
+		node.priority = priorities[i]
+		node.atime, node.ready = now, true
+		node.ttl.Store(int64(rec.Expires.Sub(rec.Time)))
+		node.once.Do(func() {}) // nothing to load
+		c.prioritise(node)
+
+		c.link(node)
+		heap.Push(&c.expiry, node)
+		c.mapNode(node)
+		c.tag(node)
+		c.deliver(node.key, node.value)
+
+		c.weight += node.weight
+		c.trim()
+	}
+}
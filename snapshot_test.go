@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	c := NewWithTTL(5, time.Hour, func(key int) (int, time.Duration, error) {
+		v, err := simpleBackend(key)
+
+		if key == 5 {
+			return v, time.Nanosecond, err
+		}
+
+		return v, 0, err
+	})
+
+	if err := fill(c.Get, []int{1, 2, 1000, 5, 3, 1}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	var buff bytes.Buffer
+
+	if err := c.Snapshot(&buff); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// errors and expired items are not saved
+	var backend tracingBackend
+
+	c2 := New(5, time.Hour, backend.fn)
+
+	if err := c2.Restore(bytes.NewReader(buff.Bytes())); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if keys := fmt.Sprint(listKeys(&c2.list)); keys != "[2 3 1]" {
+		t.Errorf("unexpected keys: %s instead of [2 3 1]", keys)
+		return
+	}
+
+	if err := fill(c2.Get, []int{1, 2, 3}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if len(backend.trace) != 0 {
+		t.Error("unexpected backend calls:", backend.trace)
+		return
+	}
+
+	// the expiration time is preserved
+	ts := c.nodes[2].ts
+
+	if node := c2.nodes[2]; !node.ts.Equal(ts) || node.lifetime() != time.Hour {
+		t.Error("unexpected timestamp or TTL:", node.ts, node.lifetime())
+		return
+	}
+
+	// restoring into a smaller cache keeps the most recent items
+	c3 := New(2, time.Hour, simpleBackend)
+
+	if err := c3.Restore(bytes.NewReader(buff.Bytes())); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if keys := fmt.Sprint(listKeys(&c3.list)); keys != "[3 1]" {
+		t.Errorf("unexpected keys: %s instead of [3 1]", keys)
+		return
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	c := New(5, time.Hour, simpleBackend)
+
+	if err := c.Restore(bytes.NewReader([]byte("garbage"))); err == nil {
+		t.Error("missing error")
+		return
+	}
+}
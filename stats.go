@@ -0,0 +1,275 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of cache statistics.
+type Stats struct {
+	Hits        uint64 // number of requests served from the cache
+	Misses      uint64 // number of requests that required fetching data from the backend
+	ExpiredHits uint64 // number of requests that found an expired item (either stale or reloaded)
+	Loads       uint64 // number of backend calls
+	LoadErrors  uint64 // number of backend calls that returned an error
+	Size        int    // current number of items in the cache
+	Capacity    int    // current capacity of the cache (see WithAdaptiveCapacity), 0 if unbounded
+	Weight      int    // current total weight of the items in the cache (see WithWeigher)
+
+	RecentHitRatio float64 // hit ratio over the rolling window of time (0 without WithHitRatioWindow)
+
+	LoadLatency LatencyHistogram // distribution of the load durations
+
+	NegativeHits uint64 // number of requests served from the cache with ErrNotFound
+	NotFound     uint64 // number of backend calls that returned ErrNotFound
+	Rejected     uint64 // number of loaded items not admitted into the cache (see WithDoorkeeper)
+	Shed         uint64 // number of loads rejected with ErrOverloaded (see WithLoadShedding)
+
+	Fallbacks      uint64 // number of fallback loader calls (see WithFallback)
+	FallbackErrors uint64 // number of fallback loader calls that returned an error
+
+	EvictedCapacity uint64 // number of items evicted to make room for others
+	EvictedExpired  uint64 // number of expired items removed
+	EvictedDeleted  uint64 // number of items deleted explicitly
+	EvictedReplaced uint64 // number of items replaced with freshly fetched ones
+	EvictedClosed   uint64 // number of items released by closing the cache
+}
+
+// Evictions returns the total number of items that have left the cache for any reason.
+func (s Stats) Evictions() uint64 {
+	return s.EvictedCapacity + s.EvictedExpired + s.EvictedDeleted + s.EvictedReplaced + s.EvictedClosed
+}
+
+// HitRatio returns the fraction of requests served from the cache, or 0 if there were
+// no requests.
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+
+	return 0
+}
+
+// Sub returns the statistics with the counters reduced by those of the given earlier statistics,
+// that is, the counts of the events between the two snapshots. The current values (size,
+// capacity, weight, and recent hit ratio) are taken from the receiver as they are.
+func (s Stats) Sub(prev Stats) Stats {
+	s.Hits -= prev.Hits
+	s.Misses -= prev.Misses
+	s.ExpiredHits -= prev.ExpiredHits
+	s.Loads -= prev.Loads
+	s.LoadErrors -= prev.LoadErrors
+	s.NegativeHits -= prev.NegativeHits
+	s.NotFound -= prev.NotFound
+	s.Rejected -= prev.Rejected
+	s.Shed -= prev.Shed
+	s.Fallbacks -= prev.Fallbacks
+	s.FallbackErrors -= prev.FallbackErrors
+	s.EvictedCapacity -= prev.EvictedCapacity
+	s.EvictedExpired -= prev.EvictedExpired
+	s.EvictedDeleted -= prev.EvictedDeleted
+	s.EvictedReplaced -= prev.EvictedReplaced
+	s.EvictedClosed -= prev.EvictedClosed
+
+	for i, n := range prev.LoadLatency {
+		s.LoadLatency[i] -= n
+	}
+
+	return s
+}
+
+// add the counters of the other statistics
+func (s *Stats) add(other Stats) {
+	s.Hits += other.Hits
+	s.Misses += other.Misses
+	s.ExpiredHits += other.ExpiredHits
+	s.Loads += other.Loads
+	s.LoadErrors += other.LoadErrors
+	s.NegativeHits += other.NegativeHits
+	s.NotFound += other.NotFound
+	s.Rejected += other.Rejected
+	s.Shed += other.Shed
+	s.Fallbacks += other.Fallbacks
+	s.FallbackErrors += other.FallbackErrors
+	s.Size += other.Size
+	s.Capacity += other.Capacity
+	s.Weight += other.Weight
+	s.EvictedCapacity += other.EvictedCapacity
+	s.EvictedExpired += other.EvictedExpired
+	s.EvictedDeleted += other.EvictedDeleted
+	s.EvictedReplaced += other.EvictedReplaced
+	s.EvictedClosed += other.EvictedClosed
+
+	for i, n := range other.LoadLatency {
+		s.LoadLatency[i] += n
+	}
+}
+
+// Stats returns a snapshot of the cache statistics.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	size, capacity, weight := len(c.nodes), c.size, c.weight
+	oldest := c.samples.oldest()
+	c.mu.Unlock()
+
+	if c.unbounded {
+		capacity = 0
+	}
+
+	hits, misses := c.stats.hits.Load(), c.stats.misses.Load()
+
+	var recent float64
+
+	if c.window > 0 {
+		recent = Stats{Hits: hits - oldest.hits, Misses: misses - oldest.misses}.HitRatio()
+	}
+
+	var latency LatencyHistogram
+
+	for i := range latency {
+		latency[i] = c.stats.latency[i].Load()
+	}
+
+	return Stats{
+		Hits:        hits,
+		Misses:      misses,
+		ExpiredHits: c.stats.expired.Load(),
+		Loads:       c.stats.loads.Load(),
+		LoadErrors:  c.stats.loadErrors.Load(),
+		Size:        size,
+		Capacity:    capacity,
+		Weight:      weight,
+
+		RecentHitRatio: recent,
+
+		LoadLatency: latency,
+
+		NegativeHits: c.stats.negative.Load(),
+		NotFound:     c.stats.notFound.Load(),
+		Rejected:     c.stats.rejected.Load(),
+		Shed:         c.stats.shed.Load(),
+
+		Fallbacks:      c.stats.fallbacks.Load(),
+		FallbackErrors: c.stats.fallbackErrors.Load(),
+
+		EvictedCapacity: c.stats.evictions[EvictCapacity].Load(),
+		EvictedExpired:  c.stats.evictions[EvictExpired].Load(),
+		EvictedDeleted:  c.stats.evictions[EvictDeleted].Load(),
+		EvictedReplaced: c.stats.evictions[EvictReplaced].Load(),
+		EvictedClosed:   c.stats.evictions[EvictClosed].Load(),
+	}
+}
+
+// StatsDelta returns the statistics with the counters accumulated since the previous call to
+// the method, or since the creation of the cache, for example, to export the metrics per scrape
+// interval. The cumulative counters returned from Stats are not affected.
+func (c *LRU[K, V]) StatsDelta() Stats {
+	return c.last.delta(c.Stats)
+}
+
+// Len returns the current number of items in the cache, including the expired and invalidated
+// items not yet removed.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.nodes)
+}
+
+// statistics at the previous call to StatsDelta
+type lastStats struct {
+	mu    sync.Mutex // serialises the calls
+	stats Stats      // previous statistics
+}
+
+// difference between the statistics from the given function and the previous ones, with the new
+// statistics becoming the previous ones
+func (l *lastStats) delta(stats func() Stats) Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := stats()
+	prev := l.stats
+	l.stats = s
+
+	return s.Sub(prev)
+}
+
+// number of hit ratio samples per window
+const numWindowSamples = 10
+
+// hit ratio samples (ring buffer)
+type hitSamples struct {
+	ring [numWindowSamples]hitSample // samples
+	next int                         // index of the next sample to write
+	full bool                        // true when the ring has wrapped around
+}
+
+// cumulative counters at some point in time
+type hitSample struct {
+	hits, misses uint64
+}
+
+// add the sample, replacing the oldest one
+func (s *hitSamples) add(sample hitSample) {
+	s.ring[s.next] = sample
+
+	if s.next++; s.next == len(s.ring) {
+		s.next, s.full = 0, true
+	}
+}
+
+// the oldest sample, or zero counters if no samples have been taken
+func (s *hitSamples) oldest() hitSample {
+	if s.full {
+		return s.ring[s.next]
+	}
+
+	return s.ring[0]
+}
+
+// hit ratio sampling goroutine
+func (c *LRU[K, V]) sampler(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.sample()
+		}
+	}
+}
+
+// take a sample of the hit and miss counters
+func (c *LRU[K, V]) sample() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.samples.add(hitSample{c.stats.hits.Load(), c.stats.misses.Load()})
+}
+
+// statistics counters
+type counters struct {
+	hits       atomic.Uint64                  // cache hits
+	misses     atomic.Uint64                  // cache misses
+	expired    atomic.Uint64                  // hits on expired items
+	loads      atomic.Uint64                  // backend calls
+	loadErrors atomic.Uint64                  // backend errors
+	notFound   atomic.Uint64                  // negative results from the backend
+	negative   atomic.Uint64                  // cache hits on negative results
+	rejected   atomic.Uint64                  // items not admitted by the doorkeeper
+	shed       atomic.Uint64                  // loads rejected under overload
+	evictions  [numEvictReasons]atomic.Uint64 // evictions per reason
+
+	latency [numLatencyBuckets]atomic.Uint64 // load latency histogram
+
+	fallbacks      atomic.Uint64 // fallback loader calls
+	fallbackErrors atomic.Uint64 // fallback loader errors
+}
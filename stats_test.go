@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	c := NewWithTTL(3, time.Hour, func(key int) (int, time.Duration, error) {
+		v, err := simpleBackend(key)
+
+		if key == 5 {
+			return v, time.Nanosecond, err
+		}
+
+		return v, 0, err
+	})
+
+	if err := fill(c.Get, []int{1000, 1, 2, 3, 4, 5, 5, 3, 3}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.Delete(4)
+	c.Delete(4)
+
+	exp := Stats{
+		Hits:            2,
+		Misses:          7,
+		ExpiredHits:     1,
+		Loads:           7,
+		LoadErrors:      1,
+		Size:            2,
+		Capacity:        3,
+		EvictedCapacity: 3,
+		EvictedExpired:  1,
+		EvictedDeleted:  1,
+	}
+
+	if s := c.Stats(); s.LoadLatency.Count() != exp.Loads || withoutLatency(s) != exp {
+		t.Errorf("unexpected stats: %+v instead of %+v", s, exp)
+		return
+	}
+
+	if n := c.Stats().Evictions(); n != 5 {
+		t.Errorf("unexpected total number of evictions: %d instead of 5", n)
+		return
+	}
+}
+
+func TestStatsDelta(t *testing.T) {
+	c := New(3, time.Hour, simpleBackend)
+
+	if err := fill(c.Get, []int{1, 2, 1}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	exp := Stats{Hits: 1, Misses: 2, Loads: 2, Size: 2, Capacity: 3}
+
+	if s := c.StatsDelta(); s.LoadLatency.Count() != exp.Loads || withoutLatency(s) != exp {
+		t.Errorf("unexpected stats: %+v instead of %+v", s, exp)
+		return
+	}
+
+	if err := fill(c.Get, []int{1, 3, 4}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	exp = Stats{Hits: 1, Misses: 2, Loads: 2, Size: 3, Capacity: 3, EvictedCapacity: 1}
+
+	if s := c.StatsDelta(); s.LoadLatency.Count() != exp.Loads || withoutLatency(s) != exp {
+		t.Errorf("unexpected stats: %+v instead of %+v", s, exp)
+		return
+	}
+
+	// cumulative counters are not affected
+	if s := c.Stats(); s.Hits != 2 || s.Misses != 4 {
+		t.Errorf("unexpected stats: %+v", s)
+		return
+	}
+}
+
+func TestHitRatio(t *testing.T) {
+	if r := (Stats{}).HitRatio(); r != 0 {
+		t.Errorf("unexpected hit ratio: %f instead of 0", r)
+		return
+	}
+
+	if r := (Stats{Hits: 3, Misses: 1}).HitRatio(); r != 0.75 {
+		t.Errorf("unexpected hit ratio: %f instead of 0.75", r)
+		return
+	}
+}
+
+func TestRecentHitRatio(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend, WithHitRatioWindow(time.Hour))
+
+	defer c.Close()
+
+	ratio := func(keys []int, exp float64) error {
+		if err := fill(c.Get, keys, validKey); err != nil {
+			return err
+		}
+
+		if r := c.Stats().RecentHitRatio; r != exp {
+			return fmt.Errorf("unexpected hit ratio: %g instead of %g", r, exp)
+		}
+
+		return nil
+	}
+
+	// 1 hit out of 4 requests
+	if err := ratio([]int{1, 2, 3, 1}, 0.25); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the window moves on
+	for range numWindowSamples {
+		c.sample()
+	}
+
+	if err := ratio([]int{1, 2, 3, 4}, 0.75); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if r := c.Stats().HitRatio(); r != 0.5 {
+		t.Errorf("unexpected overall hit ratio: %g instead of 0.5", r)
+		return
+	}
+}
+
+// the statistics without the timing dependent latency histogram
+func withoutLatency(s Stats) Stats {
+	s.LoadLatency = LatencyHistogram{}
+	return s
+}
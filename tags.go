@@ -0,0 +1,54 @@
+package cache
+
+// WithTagger sets a function to associate string tags with each value once it is fetched from
+// the backend, so that all the items carrying a tag can be evicted at once by calling
+// InvalidateTag. Errors are never tagged. The key and value types of the function must match
+// those of the cache.
+func WithTagger[K comparable, V any](fn func(K, V) []string) Option {
+	return func(cfg *config) {
+		cfg.tagger = fn
+	}
+}
+
+// InvalidateTag evicts all the items carrying the given tag, returning the number of items
+// evicted. The items are reported as deleted, but the invalidations are not published.
+func (c *LRU[K, V]) InvalidateTag(tag string) (n int) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for node := range c.tags[tag] {
+		c.drop(node, EvictDeleted)
+		n++
+	}
+
+	return
+}
+
+// add the node to the tag index
+func (c *LRU[K, V]) tag(node *lruNode[K, V]) {
+	for _, tag := range node.tags {
+		nodes := c.tags[tag]
+
+		if nodes == nil {
+			if c.tags == nil {
+				c.tags = make(map[string]map[*lruNode[K, V]]struct{})
+			}
+
+			nodes = make(map[*lruNode[K, V]]struct{})
+			c.tags[tag] = nodes
+		}
+
+		nodes[node] = struct{}{}
+	}
+}
+
+// remove the node from the tag index
+func (c *LRU[K, V]) untag(node *lruNode[K, V]) {
+	for _, tag := range node.tags {
+		if nodes := c.tags[tag]; nodes != nil {
+			if delete(nodes, node); len(nodes) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTags(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn, WithTagger(func(key, _ int) []string {
+		if key%2 == 0 {
+			return []string{"even", "all"}
+		}
+
+		return []string{"odd", "all"}
+	}))
+
+	if err := fill(c.Get, []int{1, 2, 3, 4, 5, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if n := c.InvalidateTag("even"); n != 2 {
+		t.Errorf("unexpected number of items evicted: %d instead of 2", n)
+		return
+	}
+
+	if keys := fmt.Sprint(listKeys(&c.list)); keys != "[1 3 5 1000]" {
+		t.Errorf("unexpected keys: %s instead of [1 3 5 1000]", keys)
+		return
+	}
+
+	if s := c.Stats(); s.EvictedDeleted != 2 {
+		t.Errorf("unexpected number of deletions: %d instead of 2", s.EvictedDeleted)
+		return
+	}
+
+	// the deleted items are gone from the other tags as well
+	if n := len(c.tags["all"]); n != 3 {
+		t.Errorf("unexpected number of items tagged: %d instead of 3", n)
+		return
+	}
+
+	c.Delete(1)
+
+	if n := c.InvalidateTag("all"); n != 2 {
+		t.Errorf("unexpected number of items evicted: %d instead of 2", n)
+		return
+	}
+
+	// errors are not tagged
+	if keys := fmt.Sprint(listKeys(&c.list)); keys != "[1000]" {
+		t.Errorf("unexpected keys: %s instead of [1000]", keys)
+		return
+	}
+
+	if len(c.tags) != 0 {
+		t.Error("tag index is not empty:", c.tags)
+		return
+	}
+
+	if n := c.InvalidateTag("none"); n != 0 {
+		t.Errorf("unexpected number of items evicted: %d instead of 0", n)
+		return
+	}
+}
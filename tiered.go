@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is the interface to a second-level cache shared between processes (e.g., Redis).
+type Store[K comparable, V any] interface {
+	// Get retrieves the value for the given key, with "found" set to false if the key
+	// is not in the store.
+	Get(ctx context.Context, key K) (value V, found bool, err error)
+
+	// Set stores the value for the given key, with the given time-to-live (0 if the value
+	// never expires).
+	Set(ctx context.Context, key K, value V, ttl time.Duration) error
+
+	// Delete removes the given key from the store.
+	Delete(ctx context.Context, key K) error
+}
+
+// Tiered is an opaque type representing a two-level cache, where an in-process LRU cache
+// (first level) is backed by a shared Store (second level) in front of the origin backend.
+type Tiered[K comparable, V any] struct {
+	l1 *LRU[K, V]  // in-process cache
+	l2 Store[K, V] // shared store
+}
+
+// NewTiered creates a new two-level cache with keys of type "K" and values of type "V".
+// On a miss in the first level, the value is looked up in the given store, and only if it
+// is not there, the backend function is invoked, with the fetched value then written to
+// the store with the same TTL. Store errors are treated as misses, and a failure to write
+// the value to the store does not affect the caller. Errors from the backend are never
+// written to the store. The size, TTL and options apply to the first level.
+func NewTiered[K comparable, V any](
+	size int,
+	ttl time.Duration,
+	l2 Store[K, V],
+	backend func(K) (V, error),
+	opts ...Option,
+) *Tiered[K, V] {
+	if l2 == nil {
+		panic("attempt to create a tiered cache with nil store")
+	}
+
+	if backend == nil {
+		panic("attempt to create a tiered cache with nil backend function")
+	}
+
+	storeTTL := ttl
+
+	if storeTTL == NoExpiry {
+		storeTTL = 0
+	}
+
+	return &Tiered[K, V]{
+		l1: New(size, ttl, func(key K) (value V, err error) {
+			ctx := context.Background()
+
+			value, found, err := l2.Get(ctx, key)
+
+			if err == nil && found {
+				return
+			}
+
+			if value, err = backend(key); err == nil {
+				l2.Set(ctx, key, value, storeTTL)
+			}
+
+			return
+		}, opts...),
+		l2: l2,
+	}
+}
+
+// Get retrieves the value associated with the given key, from either level of the cache,
+// or from the backend.
+func (c *Tiered[K, V]) Get(key K) (V, error) {
+	return c.l1.Get(key)
+}
+
+// Delete evicts the given key from both levels of the cache, returning the error from the store.
+func (c *Tiered[K, V]) Delete(key K) error {
+	c.l1.Delete(key)
+
+	return c.l2.Delete(context.Background(), key)
+}
+
+// Len returns the current number of items in the first level of the cache.
+func (c *Tiered[K, V]) Len() int {
+	return c.l1.Len()
+}
+
+// Stats returns statistics of the first level of the cache.
+func (c *Tiered[K, V]) Stats() Stats {
+	return c.l1.Stats()
+}
+
+// Close closes the first level of the cache (see LRU.Close). The store is not
+// closed.
+func (c *Tiered[K, V]) Close() error {
+	return c.l1.Close()
+}
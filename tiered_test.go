@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTiered(t *testing.T) {
+	var backend tracingBackend
+
+	store := newMapStore[int, int]()
+	c := NewTiered(10, time.Hour, store, backend.fn)
+
+	defer c.Close()
+
+	store.Set(context.Background(), 1, 100, time.Hour)
+
+	// the first key comes from the store, the others from the backend
+	if v, err := c.Get(1); err != nil || v != 100 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	if err := fill(c.Get, []int{2, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := matchLogs(store.log, []string{"set 1", "get 1", "get 2", "set 2", "get 1000"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(backend.trace) != 2 {
+		t.Error("unexpected backend calls:", backend.trace)
+		return
+	}
+
+	// deletion from both levels
+	if err := c.Delete(2); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err := fill(c.Get, []int{2}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if len(backend.trace) != 3 {
+		t.Error("unexpected backend calls:", backend.trace)
+		return
+	}
+
+	// store failure
+	store.err = errors.New("store failure")
+
+	if err := fill(c.Get, []int{3}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := c.Delete(3); err != store.err {
+		t.Error("unexpected error:", err)
+		return
+	}
+}
+
+// in-memory store with operation log
+type mapStore[K comparable, V any] struct {
+	mu   sync.Mutex
+	data map[K]V
+	log  []string
+	err  error
+}
+
+func newMapStore[K comparable, V any]() *mapStore[K, V] {
+	return &mapStore[K, V]{data: make(map[K]V)}
+}
+
+func (s *mapStore[K, V]) Get(_ context.Context, key K) (value V, found bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log = append(s.log, fmt.Sprint("get ", key))
+
+	if s.err != nil {
+		return value, false, s.err
+	}
+
+	value, found = s.data[key]
+	return
+}
+
+func (s *mapStore[K, V]) Set(_ context.Context, key K, value V, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log = append(s.log, fmt.Sprint("set ", key))
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.data[key] = value
+	return nil
+}
+
+func (s *mapStore[K, V]) Delete(_ context.Context, key K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log = append(s.log, fmt.Sprint("delete ", key))
+
+	if s.err != nil {
+		return s.err
+	}
+
+	delete(s.data, key)
+	return nil
+}
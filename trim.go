@@ -0,0 +1,29 @@
+package cache
+
+// Evict removes up to n least recently used items (most recently used under PolicyMRU) from
+// the cache, reporting them as evicted for capacity, for example, to shed memory in response
+// to an external memory pressure signal. Pinned items are not evicted. The capacity of the cache
+// does not change. The method returns the number of items evicted.
+func (c *LRU[K, V]) Evict(n int) (evicted int) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for evicted < n && c.evict() {
+		evicted++
+	}
+
+	return
+}
+
+// TrimToSize evicts the items chosen by the eviction policy until no more than n items are left
+// in the cache (see Evict), returning the number of items evicted.
+func (c *LRU[K, V]) TrimToSize(n int) (evicted int) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for len(c.nodes) > n && c.evict() {
+		evicted++
+	}
+
+	return
+}
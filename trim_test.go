@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEvict(t *testing.T) {
+	var log []string
+
+	c := New(10, time.Hour, simpleBackend, WithOnEvict(func(k, v int, reason EvictReason) {
+		log = append(log, fmt.Sprintf("%d:%d:%s", k, v, reason))
+	}))
+
+	if err := fill(c.Get, []int{1, 2, 3, 4, 5, 6, 1}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	c.Pin(2)
+
+	if n := c.Evict(2); n != 2 {
+		t.Error("unexpected number of evicted items:", n)
+		return
+	}
+
+	if n := c.TrimToSize(2); n != 2 {
+		t.Error("unexpected number of evicted items:", n)
+		return
+	}
+
+	// only the pinned and the most recent items are left
+	if n := c.TrimToSize(0); n != 1 {
+		t.Error("unexpected number of evicted items:", n)
+		return
+	}
+
+	if err := matchLogs(log, []string{"3:-3:capacity", "4:-4:capacity", "5:-5:capacity", "6:-6:capacity", "1:-1:capacity"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if keys := c.OrderedKeys(); len(keys) != 1 || keys[0] != 2 {
+		t.Error("unexpected keys:", keys)
+		return
+	}
+}
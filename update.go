@@ -0,0 +1,88 @@
+package cache
+
+// Update atomically replaces the value associated with the given key with the one returned from
+// the given function, which receives the current value, if any, and reports whether the value is
+// to be replaced. The function is invoked with the lock on the cache held, so it must be quick,
+// and must not call any methods of the cache. Expired items, errors and items still being loaded
+// count as not found. The new value is stored as the most recent item with the default TTL, but
+// it is never written to the backend (see WithWriter). The method returns the resulting value
+// and whether it has been replaced. After the cache has been closed the function is not invoked.
+func (c *LRU[K, V]) Update(key K, fn func(old V, found bool) (V, bool)) (value V, updated bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return
+	}
+
+	var found bool
+
+	if node := c.nodes[key]; node != nil && node.ready && node.err == nil && c.fresh(node, c.now()) {
+		value, found = node.value, true
+	}
+
+	newValue, updated := fn(value, found)
+
+	if !updated {
+		return
+	}
+
+	value = newValue
+
+	var weight int
+
+	if c.weigher != nil {
+		weight = max(c.weigher(key, value), 0)
+	}
+
+	var tags []string
+
+	if c.tagger != nil {
+		tags = c.tagger(key, value)
+	}
+
+	c.store(key, value, 0, weight, tags, c.priorityOf(key, value))
+	return
+}
+
+// GetOrSet returns the value already in the cache for the given key, with "loaded" set to true,
+// or otherwise stores the given value as the most recent item with the default TTL, and returns
+// it, with "loaded" set to false. The backend is never invoked, and the stored value is never
+// written to the backend (see WithWriter). Expired items, errors and items still being loaded
+// count as not found. After the cache has been closed the given value is returned, but not stored.
+func (c *LRU[K, V]) GetOrSet(key K, value V) (actual V, loaded bool) {
+	var weight int
+
+	if c.weigher != nil {
+		weight = max(c.weigher(key, value), 0)
+	}
+
+	var tags []string
+
+	if c.tagger != nil {
+		tags = c.tagger(key, value)
+	}
+
+	priority := c.priorityOf(key, value)
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return value, false
+	}
+
+	now := c.now()
+
+	if node := c.nodes[key]; node != nil && node.ready && node.err == nil && c.fresh(node, now) {
+		c.stats.hits.Add(1)
+		node.hits.Add(1)
+		node.atime = now
+		c.touch(node)
+
+		return node.value, true
+	}
+
+	c.store(key, value, 0, weight, tags, priority)
+	return value, false
+}
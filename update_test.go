@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdate(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn)
+
+	if err := fill(c.Get, []int{1, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	incr := func(old int, _ bool) (int, bool) { return old + 1, true }
+
+	// concurrent updates
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				c.Update(1, incr)
+				c.Update(2, incr)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for key, exp := range map[int]int{1: 999, 2: 1000} {
+		if v, err := c.Get(key); err != nil || v != exp {
+			t.Errorf("unexpected result for key %d: %d, %v", key, v, err)
+			return
+		}
+	}
+
+	// errors count as not found
+	v, updated := c.Update(1000, func(old int, found bool) (int, bool) {
+		return old, !found
+	})
+
+	if !updated || v != 0 {
+		t.Error("unexpected result:", v, updated)
+		return
+	}
+
+	// no update
+	if v, updated = c.Update(1, func(int, bool) (int, bool) { return 0, false }); updated || v != 999 {
+		t.Error("unexpected result:", v, updated)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	// closed cache
+	c.Close()
+
+	if _, updated = c.Update(1, incr); updated {
+		t.Error("unexpected update after closing")
+		return
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	var backend tracingBackend
+
+	c := New(10, time.Hour, backend.fn)
+
+	if err := fill(c.Get, []int{1, 1000}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	steps := []struct {
+		key, value, actual int
+		loaded             bool
+	}{
+		{1, 10, -1, true},
+		{2, 20, 20, false},
+		{2, 30, 20, true},
+		{1000, 40, 40, false}, // errors count as not found
+	}
+
+	for _, step := range steps {
+		actual, loaded := c.GetOrSet(step.key, step.value)
+
+		if actual != step.actual || loaded != step.loaded {
+			t.Errorf("unexpected result for key %d: %d, %t instead of %d, %t",
+				step.key, actual, loaded, step.actual, step.loaded)
+			return
+		}
+	}
+
+	if v, err := c.Get(1000); err != nil || v != 40 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 1000}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+}
@@ -0,0 +1,89 @@
+package cache
+
+// Watch subscribes to the changes of the item for the given key. The returned channel receives
+// every new value that gets into the cache for the key, whether loaded from the backend, reloaded
+// in the background (see WithRefreshAhead), or stored directly (see Put and Update), and the zero
+// value when the item gets deleted or invalidated (see Delete and InvalidateAll). Expiration,
+// eviction, and errors from the backend are not reported. The channel holds only the most recent
+// notification, so a slow receiver never blocks the cache, but may miss intermediate values.
+// The returned function cancels the subscription and closes the channel; the channel is also
+// closed when the cache is closed.
+func (c *LRU[K, V]) Watch(key K) (<-chan V, func()) {
+	ch := make(chan V, 1)
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if c.watchers == nil {
+		c.watchers = make(map[K]map[chan V]struct{})
+	}
+
+	subs := c.watchers[key]
+
+	if subs == nil {
+		subs = make(map[chan V]struct{})
+		c.watchers[key] = subs
+	}
+
+	subs[ch] = struct{}{}
+
+	return ch, func() {
+		c.mu.Lock()
+		defer c.unlock()
+
+		// the channel is gone if the cache has been closed
+		if subs := c.watchers[key]; subs != nil {
+			if _, found := subs[ch]; found {
+				delete(subs, ch)
+				close(ch)
+
+				if len(subs) == 0 {
+					delete(c.watchers, key)
+				}
+			}
+		}
+	}
+}
+
+// send the value to the watchers of the key, replacing the undelivered one, if any
+// (the cache must be locked)
+func (c *LRU[K, V]) deliver(key K, value V) {
+	for ch := range c.watchers[key] {
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- value // never blocks, as only the locked cache writes to the channel
+	}
+}
+
+// notify the watchers of the keys of the valid items that are about to be invalidated
+// (the cache must be locked)
+func (c *LRU[K, V]) deliverInvalidation() {
+	var zero V
+
+	for key := range c.watchers {
+		if node := c.nodes[key]; node != nil && node.ready && node.err == nil && c.current(node) {
+			c.deliver(key, zero)
+		}
+	}
+}
+
+// close all the watcher channels (the cache must be locked)
+func (c *LRU[K, V]) closeWatchers() {
+	for _, subs := range c.watchers {
+		for ch := range subs {
+			close(ch)
+		}
+
+		clear(subs)
+	}
+
+	c.watchers = nil
+}
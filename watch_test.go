@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	c := New(10, time.Hour, simpleBackend)
+
+	ch, cancel := c.Watch(1)
+
+	// loaded value
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	if err := expectWatched(ch, -1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// only the most recent value is kept
+	if err := c.Put(context.Background(), 1, 10); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	c.Update(1, func(v int, _ bool) (int, bool) { return v + 1, true })
+
+	if err := expectWatched(ch, 11); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// deletion
+	c.Delete(2)
+	c.Delete(1)
+
+	if err := expectWatched(ch, 0); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// invalidation
+	if err := fill(c.Get, []int{1}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := expectWatched(ch, -1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	c.InvalidateAll()
+
+	if err := expectWatched(ch, 0); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the invalid item is removed without notification
+	if err := fill(c.Get, []int{1}, validKey); err != nil {
+		t.Error("error reading the cache:", err)
+		return
+	}
+
+	if err := expectWatched(ch, -1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	cancel()
+	cancel()
+
+	if _, ok := <-ch; ok {
+		t.Error("the channel has not been closed")
+		return
+	}
+}
+
+func TestWatchRefresh(t *testing.T) {
+	var calls int
+
+	backend := func(key int) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	c := New(10, 20*time.Millisecond, backend, WithStaleWhileRevalidate(time.Hour))
+
+	ch, _ := c.Watch(1)
+
+	if _, err := c.Get(1); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err := expectWatched(ch, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// stale value, reloaded in the background
+	if v, err := c.Get(1); err != nil || v != 1 {
+		t.Errorf("unexpected stale result: (%d, %v)", v, err)
+		return
+	}
+
+	select {
+	case v := <-ch:
+		if v != 2 {
+			t.Error("unexpected refreshed value:", v)
+			return
+		}
+	case <-time.After(time.Second):
+		t.Error("the value has not been refreshed")
+		return
+	}
+
+	// closing the cache closes the channel
+	if err := c.Close(); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if _, ok := <-ch; ok {
+		t.Error("the channel has not been closed")
+		return
+	}
+}
+
+// check that the channel holds exactly the given value
+func expectWatched(ch <-chan int, exp int) error {
+	select {
+	case v := <-ch:
+		if v != exp {
+			return fmt.Errorf("unexpected notification: %d instead of %d", v, exp)
+		}
+	default:
+		return fmt.Errorf("missing notification of %d", exp)
+	}
+
+	select {
+	case v := <-ch:
+		return fmt.Errorf("unexpected extra notification: %d", v)
+	default:
+		return nil
+	}
+}
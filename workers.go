@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQueueTimeout is returned from the cache when a backend call has been waiting in the queue
+// of the worker pool (see WithWorkerPool) for longer than the timeout. The error is not cached.
+var ErrQueueTimeout = errors.New("backend call queue timeout")
+
+// WithWorkerPool makes the cache invoke the backend on a pool of the given number of worker
+// goroutines, instead of the goroutines of the callers, with the calls waiting for a free worker
+// in a queue of the given capacity. A call that has not started within the given timeout, either
+// waiting for room in the queue, or in the queue itself, fails with ErrQueueTimeout, while zero
+// timeout means no limit. The pool applies to all the backend calls, including those made on
+// behalf of GetAsync, Prefetch, and background reloads, so that the parallelism of the backend
+// calls is predictable. The workers are stopped by the Close method of the cache, with the calls
+// still in the queue failing with ErrClosed.
+func WithWorkerPool(workers, queue int, timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.workers = workerConfig{workers, queue, timeout}
+	}
+}
+
+// worker pool parameters
+type workerConfig struct {
+	workers int           // number of workers (0 if disabled)
+	queue   int           // queue capacity
+	timeout time.Duration // max. time in the queue (0 if unlimited)
+}
+
+// states of a queued backend call
+const (
+	jobQueued int32 = iota
+	jobStarted
+	jobCancelled
+)
+
+// backend call queued for a worker
+type job struct {
+	run   func()        // the call
+	state atomic.Int32  // one of the job states
+	done  chan struct{} // closed when the call completes
+}
+
+// worker goroutine
+func (c *LRU[K, V]) worker() {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case j := <-c.jobs:
+			if j.state.CompareAndSwap(jobQueued, jobStarted) {
+				j.run()
+				close(j.done)
+			}
+		}
+	}
+}
+
+// invoke the backend function on the worker pool, waiting for the result
+func (c *LRU[K, V]) dispatch(backend func(K) (V, time.Duration, error), key K) (value V, ttl time.Duration, err error) {
+	var p any
+
+	j := &job{
+		run: func() {
+			defer func() { p = recover() }() // to be re-raised by the caller
+
+			value, ttl, err = backend(key)
+		},
+		done: make(chan struct{}),
+	}
+
+	var timeout <-chan time.Time
+
+	if c.workers.timeout > 0 {
+		timer := time.NewTimer(c.workers.timeout)
+
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case c.jobs <- j:
+		// queued
+	case <-timeout:
+		return value, ttl, ErrQueueTimeout
+	case <-c.done:
+		return value, ttl, ErrClosed
+	}
+
+	select {
+	case <-j.done:
+	case <-timeout:
+		if j.state.CompareAndSwap(jobQueued, jobCancelled) {
+			return value, ttl, ErrQueueTimeout
+		}
+
+		<-j.done
+	case <-c.done:
+		if j.state.CompareAndSwap(jobQueued, jobCancelled) {
+			return value, ttl, ErrClosed
+		}
+
+		<-j.done
+	}
+
+	if p != nil {
+		panic(p)
+	}
+
+	return
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	var running, peak atomic.Int32
+
+	backend := func(key int) (int, error) {
+		n := running.Add(1)
+
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+
+		return -key, nil
+	}
+
+	c := New(100, time.Hour, backend, WithWorkerPool(2, 100, 0))
+
+	defer c.Close()
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 20)
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if v, err := c.Get(i); err != nil || v != -i {
+				errs <- errors.New("unexpected result")
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		t.Error(err)
+		return
+	}
+
+	if p := peak.Load(); p != 2 {
+		t.Error("unexpected number of concurrent backend calls:", p)
+		return
+	}
+}
+
+func TestWorkerPoolTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+
+	backend := func(key int) (int, error) {
+		switch key {
+		case 1:
+			close(started)
+			<-release
+		case 3:
+			panic("oops")
+		}
+
+		return -key, nil
+	}
+
+	c := New(10, time.Hour, backend, WithWorkerPool(1, 1, 10*time.Millisecond), WithPanicRetry())
+
+	done := make(chan error)
+
+	go func() {
+		_, err := c.Get(1)
+		done <- err
+	}()
+
+	<-started
+
+	// the only worker is busy
+	if _, err := c.Get(2); err != ErrQueueTimeout {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// the error is not cached
+	if v, err := c.Get(2); err != nil || v != -2 {
+		t.Errorf("unexpected result: (%d, %v)", v, err)
+		return
+	}
+
+	// the panic is passed to the caller
+	var pe *PanicError
+
+	if _, err := c.Get(3); !errors.As(err, &pe) || pe.Value != "oops" {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	c.Close()
+
+	if _, err := c.Get(4); err != ErrClosed {
+		t.Error("unexpected error after close:", err)
+		return
+	}
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// Put writes the given value to the backend store using the writer function of the cache
+// (see WithWriter), and, if the write succeeds, replaces the item in the cache with the new
+// value, as the most recent one, with the default TTL. Without the writer function the value is
+// only stored in the cache. The invalidation of the key gets published if the cache has been
+// created with WithInvalidationPublisher option, so that the other replicas drop their copies.
+// A load of the key in progress is not affected, but its result gets discarded. In the
+// write-behind mode (see WithWriteBack) the write is queued instead, and the method only
+// returns an error if the cache has been closed.
+func (c *LRU[K, V]) Put(ctx context.Context, key K, value V) error {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+
+	if c.writer != nil && c.writeBack == 0 {
+		if err := c.writer(ctx, key, value); err != nil {
+			return err
+		}
+	}
+
+	if err := c.set(key, value, 0, true); err != nil {
+		return err
+	}
+
+	if c.publish != nil {
+		c.publish(key)
+	}
+
+	return nil
+}
+
+// store the value in the cache with the given TTL (0 for the default), replacing the existing item,
+// if any, and queueing the write of the value if required
+func (c *LRU[K, V]) set(key K, value V, ttl time.Duration, write bool) error {
+	var weight int
+
+	if c.weigher != nil {
+		weight = max(c.weigher(key, value), 0)
+	}
+
+	var tags []string
+
+	if c.tagger != nil {
+		tags = c.tagger(key, value)
+	}
+
+	priority := c.priorityOf(key, value)
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	if write && c.dirty != nil {
+		c.dirty[key] = value
+	}
+
+	c.applyHits()
+	c.store(key, value, ttl, weight, tags, priority)
+	return nil
+}
+
+// add the new node with the given value and TTL, replacing the existing one, if any (the cache must
+// be locked)
+func (c *LRU[K, V]) store(key K, value V, ttl time.Duration, weight int, tags []string, priority Priority) {
+	if c.failures != nil {
+		delete(c.failures, key)
+	}
+
+	switch old := c.nodes[key]; {
+	case old != nil:
+		c.unlink(old, EvictReplaced)
+	case len(c.nodes) >= c.size:
+		c.evict()
+	}
+
+	node := c.newNode(key, c.now())
+
+	node.value, node.weight, node.tags, node.ready = value, weight, tags, true
+	node.priority = priority
+	node.once.Do(func() {}) // nothing to load
+	c.prioritise(node)
+
+	if ttl > 0 {
+		node.ttl.Store(int64(c.jittered(ttl)))
+	}
+
+	c.link(node)
+	heap.Push(&c.expiry, node)
+	c.mapNode(node)
+	c.tag(node)
+	c.deliver(node.key, node.value)
+
+	c.weight += node.weight
+	c.trim()
+}
+
+// Flush writes all the queued values to the backend (see WithWriteBack), stopping at the first
+// error, which is then returned. The values not written stay in the queue, unless superseded by
+// newer ones, to be retried on the next flush. Without the write-behind mode the method does
+// nothing.
+func (c *LRU[K, V]) Flush(ctx context.Context) (err error) {
+	if c.writeBack == 0 {
+		return nil
+	}
+
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	c.mu.Lock()
+	batch := c.dirty
+	c.dirty, c.flushing = make(map[K]V), batch
+	c.mu.Unlock()
+
+	for key, value := range batch {
+		if err == nil {
+			if err = ctx.Err(); err == nil {
+				err = c.writer(ctx, key, value)
+			}
+		}
+
+		if err == nil {
+			delete(batch, key)
+		}
+	}
+
+	// requeue the values not written
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, value := range batch {
+		if _, found := c.dirty[key]; !found {
+			c.dirty[key] = value
+		}
+	}
+
+	c.flushing = nil
+	return
+}
+
+// queued value for the given key, if any (in write-back mode only)
+func (c *LRU[K, V]) queued(key K) (value V, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if value, found = c.dirty[key]; !found {
+		value, found = c.flushing[key]
+	}
+
+	return
+}
+
+// write-back flusher goroutine
+func (c *LRU[K, V]) flusher(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.Flush(context.Background()) // the failed writes are retried on the next tick
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPut(t *testing.T) {
+	var (
+		backend   tracingBackend
+		store     = make(map[int]int)
+		published []int
+		evicted   []string
+	)
+
+	errWrite := errors.New("write failed")
+
+	c := New(3, time.Hour, backend.fn,
+		WithWriter(func(_ context.Context, key, value int) error {
+			if key < 0 {
+				return errWrite
+			}
+
+			store[key] = value
+			return nil
+		}),
+		WithInvalidationPublisher(func(key int) { published = append(published, key) }),
+		WithOnEvict(func(key, value int, reason EvictReason) {
+			evicted = append(evicted, fmt.Sprintf("%d:%d:%s", key, value, reason))
+		}))
+
+	if err := fill(c.Get, []int{1, 2}, validKey); err != nil {
+		t.Error("error filling the cache:", err)
+		return
+	}
+
+	ctx := context.Background()
+
+	for _, key := range []int{1, 3} {
+		if err := c.Put(ctx, key, key*10); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	if err := c.Put(ctx, -1, 0); err != errWrite {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	// the values are written to the store and to the cache
+	if s := fmt.Sprint(store); s != "map[1:10 3:30]" {
+		t.Error("unexpected store content:", s)
+		return
+	}
+
+	if keys := fmt.Sprint(listKeys(&c.list)); keys != "[2 1 3]" {
+		t.Errorf("unexpected keys: %s instead of [2 1 3]", keys)
+		return
+	}
+
+	for _, key := range []int{1, 3} {
+		if v, err := c.Get(key); err != nil || v != key*10 {
+			t.Error("unexpected result:", v, err)
+			return
+		}
+	}
+
+	if err := matchTraces(backend.trace, []int{1, 2}); err != nil {
+		t.Error("invalid trace:", err)
+		return
+	}
+
+	if s := fmt.Sprint(published, evicted); s != "[1 3] [1:-1:replaced]" {
+		t.Error("unexpected invalidations or evictions:", s)
+		return
+	}
+
+	// no writes after closing
+	c.Close()
+
+	if err := c.Put(ctx, 4, 40); err != ErrClosed {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if _, found := store[4]; found {
+		t.Error("unexpected write after closing")
+		return
+	}
+}
+
+func TestWriteBack(t *testing.T) {
+	var (
+		backend tracingBackend
+		store   = make(map[int]int)
+		fail    bool
+	)
+
+	errWrite := errors.New("write failed")
+
+	c := New(2, time.Hour, backend.fn,
+		WithWriter(func(_ context.Context, key, value int) error {
+			if fail {
+				return errWrite
+			}
+
+			store[key] = value
+			return nil
+		}),
+		WithWriteBack(time.Hour))
+
+	ctx := context.Background()
+
+	for _, key := range []int{1, 2, 3, 1} {
+		if err := c.Put(ctx, key, key*10); err != nil {
+			t.Error("unexpected error:", err)
+			return
+		}
+	}
+
+	if len(store) != 0 {
+		t.Error("unexpected writes:", store)
+		return
+	}
+
+	// the evicted item is served from the queue
+	if v, err := c.Get(2); err != nil || v != 20 {
+		t.Error("unexpected result:", v, err)
+		return
+	}
+
+	if len(backend.trace) != 0 {
+		t.Error("unexpected backend calls:", backend.trace)
+		return
+	}
+
+	// failed flush
+	fail = true
+
+	if err := c.Flush(ctx); err != errWrite {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if len(c.dirty) != 3 {
+		t.Errorf("unexpected number of queued writes: %d instead of 3", len(c.dirty))
+		return
+	}
+
+	fail = false
+
+	if err := c.Put(ctx, 1, 100); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err := c.Flush(ctx); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if s := fmt.Sprint(store); s != "map[1:100 2:20 3:30]" {
+		t.Error("unexpected store content:", s)
+		return
+	}
+
+	// closing flushes the queue
+	if err := c.Put(ctx, 4, 40); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if err := c.Close(); err != nil {
+		t.Error("unexpected error:", err)
+		return
+	}
+
+	if s := fmt.Sprint(store); s != "map[1:100 2:20 3:30 4:40]" {
+		t.Error("unexpected store content:", s)
+		return
+	}
+
+	// invalid configuration
+	if _, err := TryNew(10, time.Hour, simpleBackend, WithWriteBack(time.Second)); !errors.Is(err, ErrInvalidOption) {
+		t.Error("unexpected error:", err)
+		return
+	}
+}